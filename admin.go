@@ -0,0 +1,162 @@
+package xtorm
+
+import (
+	"errors"
+
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+// Object is an ordered list of key, value pairs, for structured arguments such as those of admin commands.
+type Object = xproto.Object
+
+// ObjectField is a key, value pair of an Object.
+type ObjectField = xproto.ObjectField
+
+// Array is a list of values, for structured arguments such as those of admin commands.
+type Array = xproto.Array
+
+// AsObject opts v, a map with string keys such as map[string]string, into being passed as a structured OBJECT argument.
+// map[string]interface{} arguments are passed as OBJECTs without opting in.
+func AsObject(v interface{}) xproto.AppendAny {
+	return xproto.AnyObject(v)
+}
+
+// AsArray opts v, a slice or array such as []string, into being passed as a structured ARRAY argument.
+// []interface{} arguments are passed as ARRAYs without opting in.
+func AsArray(v interface{}) xproto.AppendAny {
+	return xproto.AnyArray(v)
+}
+
+// AdminCommand appends the X Plugin admin command cmd, executed in the mysqlx namespace, with optional args.
+// Prefer the specific methods, eg CreateCollection, ListObjects.
+func (b *Builder) AdminCommand(cmd string, args Object) {
+	b.adminCommand("AdminCommand", Table{}, cmd, args)
+}
+
+// adminCommand appends the X Plugin admin command cmd, with args, recorded as an operation of kind.
+func (b *Builder) adminCommand(kind string, table Table, cmd string, args xproto.Object) {
+	if b.disabled {
+		panic(kind + " called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	b.buf, b.err = xproto.AdminCommand(b.buf, cmd, args)
+	b.record(kind, table.String(), start)
+}
+
+// requireSchema fails the unit of work if table is not schema qualified, as required by collection admin commands.
+func (b *Builder) requireSchema(table Table) {
+	if table.Schema == "" && b.err == nil {
+		b.err = errors.New("collection schema required")
+	}
+}
+
+// collectionArgs returns the arguments identifying a collection to admin commands.
+func (b *Builder) collectionArgs(table Table) xproto.Object {
+	b.requireSchema(table)
+	return xproto.Object{{Key: "schema", Value: table.Schema}, {Key: "name", Value: table.Name}}
+}
+
+// CreateCollection creates the collection table, in its schema, which is required.
+func (b *Builder) CreateCollection(table Table) {
+	b.adminCommand("CreateCollection", table, "create_collection", b.collectionArgs(table))
+}
+
+// EnsureCollection creates the collection table, in its schema, which is required, if it does not already exist.
+func (b *Builder) EnsureCollection(table Table) {
+	b.adminCommand("EnsureCollection", table, "ensure_collection", b.collectionArgs(table))
+}
+
+// DropCollection drops the collection table, in its schema, which is required.
+func (b *Builder) DropCollection(table Table) {
+	b.adminCommand("DropCollection", table, "drop_collection", b.collectionArgs(table))
+}
+
+// ListObjects lists the tables, views and collections in schema, or the default schema if empty, with names matching
+// the LIKE pattern if not empty. The response has a result set of name and type columns.
+func (b *Builder) ListObjects(schema, pattern string) {
+	var args xproto.Object
+	if schema != "" {
+		args = append(args, xproto.ObjectField{Key: "schema", Value: schema})
+	}
+	if pattern != "" {
+		args = append(args, xproto.ObjectField{Key: "pattern", Value: pattern})
+	}
+	b.adminCommand("ListObjects", Table{Schema: schema}, "list_objects", args)
+}
+
+// IndexField is a field of the documents of a collection to index.
+type IndexField struct {
+	// Member is the path of the field within documents, eg "$.name"
+	Member string
+	// Type is the SQL type of the indexed values, eg "TEXT(20)", "INT UNSIGNED", "GEOJSON"
+	Type string
+	// Required fails inserting documents without the field
+	Required bool
+	// Array indexes each element of an array field, a multi-valued index
+	Array bool
+}
+
+// CollectionIndex describes an index of a collection.
+type CollectionIndex struct {
+	Name string
+	// Spatial indexes GEOJSON fields
+	Spatial bool
+	Fields  []IndexField
+}
+
+// CreateCollectionIndex creates index on the collection table, in its schema, which is required.
+func (b *Builder) CreateCollectionIndex(table Table, index CollectionIndex) {
+	b.requireSchema(table)
+	indexType := "INDEX"
+	if index.Spatial {
+		indexType = "SPATIAL"
+	}
+	fields := make(xproto.Array, len(index.Fields))
+	for i, f := range index.Fields {
+		field := xproto.Object{
+			{Key: "member", Value: f.Member},
+			{Key: "type", Value: f.Type},
+			{Key: "required", Value: f.Required},
+		}
+		if f.Array {
+			field = append(field, xproto.ObjectField{Key: "array", Value: true})
+		}
+		fields[i] = field
+	}
+	b.adminCommand("CreateCollectionIndex", table, "create_collection_index", xproto.Object{
+		{Key: "schema", Value: table.Schema},
+		{Key: "collection", Value: table.Name},
+		{Key: "name", Value: index.Name},
+		{Key: "type", Value: indexType},
+		{Key: "fields", Value: fields},
+	})
+}
+
+// DropCollectionIndex drops the index named name from the collection table, in its schema, which is required.
+func (b *Builder) DropCollectionIndex(table Table, name string) {
+	b.requireSchema(table)
+	b.adminCommand("DropCollectionIndex", table, "drop_collection_index", xproto.Object{
+		{Key: "schema", Value: table.Schema},
+		{Key: "collection", Value: table.Name},
+		{Key: "name", Value: name},
+	})
+}
+
+// ListClients lists the clients connected to the X Plugin. The response has a result set of client_id, user, host
+// and sql_session columns.
+func (b *Builder) ListClients() {
+	b.adminCommand("ListClients", Table{}, "list_clients", nil)
+}
+
+// KillClient disconnects the client with id, as returned from ListClients.
+func (b *Builder) KillClient(id uint64) {
+	b.adminCommand("KillClient", Table{}, "kill_client", xproto.Object{{Key: "id", Value: id}})
+}
+
+// Ping checks the session is alive.
+func (b *Builder) Ping() {
+	b.adminCommand("Ping", Table{}, "ping", nil)
+}
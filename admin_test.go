@@ -0,0 +1,71 @@
+package xtorm
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_sql"
+)
+
+func TestAdminCommands(t *testing.T) {
+	orders := Table{Schema: "shop", Name: "orders"}
+
+	x := New(bufferSize)
+	x.EnsureCollection(orders)
+	x.CreateCollectionIndex(orders, CollectionIndex{Name: "status", Fields: []IndexField{
+		{Member: "$.status", Type: "TEXT(10)", Required: true},
+		{Member: "$.tags", Type: "CHAR(20)", Array: true},
+	}})
+	x.ListObjects("shop", "ord%")
+	x.ListClients()
+	x.KillClient(42)
+	x.Ping()
+	if x.err != nil {
+		t.Fatalf("failed to build: %s", x.err)
+	}
+
+	tests := []struct {
+		stmt string
+		args int
+	}{
+		{"ensure_collection", 2},
+		{"create_collection_index", 5},
+		{"list_objects", 2},
+		{"list_clients", 0},
+		{"kill_client", 1},
+		{"ping", 0},
+	}
+	for i, tt := range tests {
+		var s mysqlx_sql.StmtExecute
+
+		if err := proto.Unmarshal(testMessage(x.buf, i)[5:], &s); err != nil {
+			t.Fatalf("unmarshal failed: %s", err)
+		}
+		if string(s.GetStmt()) != tt.stmt || s.GetNamespace() != "mysqlx" {
+			t.Fatalf("expected %s in mysqlx namespace, got %s in %s", tt.stmt, s.GetStmt(), s.GetNamespace())
+		}
+		if tt.args == 0 {
+			if len(s.GetArgs()) != 0 {
+				t.Fatalf("%s: unexpected arguments %v", tt.stmt, s.GetArgs())
+			}
+			continue
+		}
+		if n := len(s.GetArgs()[0].GetObj().GetFld()); n != tt.args {
+			t.Fatalf("%s: expected %d arguments, got %d", tt.stmt, tt.args, n)
+		}
+	}
+
+	var s mysqlx_sql.StmtExecute
+	if err := proto.Unmarshal(testMessage(x.buf, 1)[5:], &s); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	fields := s.GetArgs()[0].GetObj().GetFld()[4]
+	if fields.GetKey() != "fields" || len(fields.GetValue().GetArray().GetValue()) != 2 {
+		t.Fatalf("unexpected index fields %v", fields)
+	}
+	tags := fields.GetValue().GetArray().GetValue()[1].GetObj().GetFld()
+	if len(tags) != 4 || tags[3].GetKey() != "array" || !tags[3].GetValue().GetScalar().GetVBool() {
+		t.Fatalf("unexpected index field %v", tags)
+	}
+}
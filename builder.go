@@ -26,6 +26,13 @@ type Builder struct {
 	buf      []byte
 	err      error
 	disabled bool
+
+	// Number of messages in buf[:scanned], maintained by count()
+	scanned  int
+	messages int
+
+	ops   []Op
+	label string
 }
 
 func (b *Builder) call(f func(b *Builder) error) {
@@ -34,14 +41,73 @@ func (b *Builder) call(f func(b *Builder) error) {
 		buf:      b.buf,
 		err:      b.err,
 		disabled: false,
+		scanned:  b.scanned,
+		messages: b.messages,
+		ops:      b.ops,
+		label:    b.label,
 	}
 	b.disabled = true
 	f(&child)
 	b.buf = child.buf
 	b.err = child.err
+	b.scanned = child.scanned
+	b.messages = child.messages
+	b.ops = child.ops
+	b.label = child.label
 	b.disabled = false
 }
 
+// Ref returns a reference to the next message appended to the builder, for locating its response.
+func (b *Builder) Ref() Ref {
+	if b.disabled {
+		panic("Ref called on non child")
+	}
+	return Ref(b.count())
+}
+
+// Ref is the position of a message within a unit of work, and of its response in the responses returned from Send.
+type Ref int
+
+// Response returns the response to the referenced message, or false if the message was not responded to.
+func (r Ref) Response(responses []netx.Response) (netx.Response, bool) {
+	if int(r) < 0 || int(r) >= len(responses) {
+		return netx.Response{}, false
+	}
+	return responses[r], true
+}
+
+// Rows returns the first result set of the response to the referenced message.
+func (r Ref) Rows(responses []netx.Response) (*Rows, error) {
+	resp, ok := r.Response(responses)
+	if !ok {
+		return nil, errors.New("no response for message " + strconv.Itoa(int(r)))
+	}
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	if len(resp.ResultSets) == 0 {
+		return nil, errors.New("no result set in response to " + resp.Type.String())
+	}
+	return NewRows(resp.ResultSets[0]), nil
+}
+
+// ResultSets returns Rows for each result set of the response to the referenced message, such as those of a stored
+// procedure CALL, the last being the OUT parameters if the procedure has any.
+func (r Ref) ResultSets(responses []netx.Response) ([]*Rows, error) {
+	resp, ok := r.Response(responses)
+	if !ok {
+		return nil, errors.New("no response for message " + strconv.Itoa(int(r)))
+	}
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	rows := make([]*Rows, len(resp.ResultSets))
+	for i, rs := range resp.ResultSets {
+		rows[i] = NewRows(rs)
+	}
+	return rows, nil
+}
+
 type OpenContext uint8
 
 const (
@@ -56,9 +122,13 @@ func (b *Builder) ExpectFailOnError(context OpenContext, f func(b *Builder) erro
 	if b.err != nil {
 		return
 	}
+	start := b.count()
 	b.buf = xproto.ExpectOpen(b.buf, xproto.OpenCtxOperation(context), xproto.OpenConditionExpectNoError(true))
+	b.record("ExpectOpen", "", start)
 	b.call(f)
+	start = b.count()
 	b.buf = xproto.ExpectClose(b.buf)
+	b.record("ExpectClose", "", start)
 }
 
 // StmtExecute,
@@ -69,7 +139,19 @@ func (b *Builder) StmtExecute(stmt string, args ...interface{}) {
 	if b.err != nil {
 		return
 	}
+	start := b.count()
 	b.buf, b.err = xproto.StmtExecute(b.buf, stmt, args)
+	b.record("StmtExecute", "", start)
+}
+
+// stmtExecute executes a statement without arguments, recorded as an operation of kind.
+func (b *Builder) stmtExecute(kind string, stmt string) {
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	b.buf, b.err = xproto.StmtExecute(b.buf, stmt, nil)
+	b.record(kind, "", start)
 }
 
 // Prepare, prepare a statement with a given id.
@@ -80,7 +162,39 @@ func (b *Builder) Prepare(id uint32, stmt string) {
 	if b.err != nil {
 		return
 	}
+	start := b.count()
 	b.buf = xproto.Prepare(b.buf, id, stmt)
+	b.record("Prepare", "", start)
+}
+
+// PrepareCrud, prepare the single Find, Insert, Update or Delete appended by f with a given id.
+// Use Placeholder() for values to be supplied to Execute.
+func (b *Builder) PrepareCrud(id uint32, f func(b *Builder) error) {
+	if b.disabled {
+		panic("PrepareCrud called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	n := len(b.buf)
+	ops := len(b.ops)
+	b.call(f)
+	if b.err != nil {
+		return
+	}
+	if b.count() != start+1 {
+		b.err = errors.New("PrepareCrud expects a single Find, Insert, Update or Delete")
+		return
+	}
+	b.buf, b.err = xproto.PrepareCrud(b.buf, n, id)
+	if b.err != nil {
+		return
+	}
+	b.rescanned()
+	if len(b.ops) == ops+1 {
+		b.ops[ops].Kind = "Prepare" + b.ops[ops].Kind
+	}
 }
 
 // Execute, executes a prepare statement with given arguments.
@@ -91,7 +205,9 @@ func (b *Builder) Execute(id uint32, args ...interface{}) {
 	if b.err != nil {
 		return
 	}
+	start := b.count()
 	b.buf, b.err = xproto.Execute(b.buf, id, args)
+	b.record("Execute", "", start)
 }
 
 // Deallocate, deallocate a prepared statement.
@@ -102,20 +218,28 @@ func (b *Builder) Deallocate(id uint32) {
 	if b.err != nil {
 		return
 	}
+	start := b.count()
 	b.buf = xproto.Deallocate(b.buf, id)
+	b.record("Deallocate", "", start)
+}
+
+// Insert, inserts rows of data into the columns of table.
+func (b *Builder) Insert(table string, columns []string, data [][]interface{}) {
+	b.InsertTable(Table{Name: table}, columns, data)
 }
 
-// Insert, insert
-func (b *Builder) Insert(name string, columns []string, data [][]interface{}) {
+// InsertTable is as Insert, upon table, which may be schema qualified.
+func (b *Builder) InsertTable(table Table, columns []string, data [][]interface{}) {
 	if b.disabled {
 		panic("Insert called on non child")
 	}
 	if b.err != nil {
 		return
 	}
+	start := b.count()
 	n := len(b.buf)
 
-	b.buf = xproto.Insert(b.buf, name, columns)
+	b.buf = xproto.Insert(b.buf, table.Schema, table.Name, columns)
 	for i, row := range data {
 		if len(row) != len(columns) {
 			b.err = fmt.Errorf("unexpected number of values in row %d, expected %d, got %d", i, len(columns), len(row))
@@ -127,9 +251,15 @@ func (b *Builder) Insert(name string, columns []string, data [][]interface{}) {
 		}
 	}
 	binary.LittleEndian.PutUint32(b.buf[n:], uint32(len(b.buf)-n-4))
+	b.record("Insert", table.String(), start)
+}
+
+func (b *Builder) InsertRow(table string, columns []string, row []interface{}) xproto.AppendExprFunc {
+	return b.InsertRowTable(Table{Name: table}, columns, row)
 }
 
-func (b *Builder) InsertRow(name string, columns []string, row []interface{}) xproto.AppendExprFunc {
+// InsertRowTable is as InsertRow, upon table, which may be schema qualified.
+func (b *Builder) InsertRowTable(table Table, columns []string, row []interface{}) xproto.AppendExprFunc {
 
 	if b.disabled {
 		panic("InsertID called on non child")
@@ -137,8 +267,9 @@ func (b *Builder) InsertRow(name string, columns []string, row []interface{}) xp
 	if b.err != nil {
 		return nil
 	}
+	start := b.count()
 	n := len(b.buf)
-	b.buf = xproto.Insert(b.buf, name, columns)
+	b.buf = xproto.Insert(b.buf, table.Schema, table.Name, columns)
 	b.buf, b.err = xproto.AppendInsertRow(b.buf, row)
 	if b.err != nil {
 		return nil
@@ -147,22 +278,29 @@ func (b *Builder) InsertRow(name string, columns []string, row []interface{}) xp
 
 	// Generate unique variable name to store the last insert id() in.
 	id := "@id$" + strconv.Itoa(len(b.buf))
-	b.StmtExecute("SET " + id + " = LAST_INSERT_ID()")
+	b.buf, b.err = xproto.StmtExecute(b.buf, "SET "+id+" = LAST_INSERT_ID()", nil)
+	b.record("InsertRow", table.String(), start)
 	return func(p []byte, tag uint8) ([]byte, error) {
 		return xproto.AppendExprVariable(p, tag, id)
 	}
 }
 
-func (b *Builder) Update(name string, criteria exprFunc, set map[string]interface{}) {
+func (b *Builder) Update(table string, criteria exprFunc, set map[string]interface{}) {
+	b.UpdateTable(Table{Name: table}, criteria, set)
+}
+
+// UpdateTable is as Update, upon table, which may be schema qualified.
+func (b *Builder) UpdateTable(table Table, criteria exprFunc, set map[string]interface{}) {
 	if b.disabled {
 		panic("Update called on non child")
 	}
 	if b.err != nil {
 		return
 	}
+	start := b.count()
 	n := len(b.buf)
 
-	b.buf, b.err = xproto.Update(b.buf, name, criteria)
+	b.buf, b.err = xproto.Update(b.buf, table.Schema, table.Name, criteria)
 	if b.err != nil {
 		return
 	}
@@ -173,18 +311,57 @@ func (b *Builder) Update(name string, criteria exprFunc, set map[string]interfac
 		}
 	}
 	binary.LittleEndian.PutUint32(b.buf[n:], uint32(len(b.buf)-n-4))
+	b.record("Update", table.String(), start)
 }
 
-// Delete, deletes rows from table named name, and that match the expression criteria.
+// Delete, deletes rows from table, and that match the expression criteria.
 // Using nil criteria deletes all rows from a table.
-func (b *Builder) Delete(name string, criteria exprFunc) {
+func (b *Builder) Delete(table string, criteria exprFunc) {
+	b.DeleteTable(Table{Name: table}, criteria)
+}
+
+// DeleteTable is as Delete, upon table, which may be schema qualified.
+func (b *Builder) DeleteTable(table Table, criteria exprFunc) {
 	if b.disabled {
 		panic("Delete called on non child")
 	}
 	if b.err != nil {
 		return
 	}
-	b.buf, b.err = xproto.Delete(b.buf, name, criteria)
+	start := b.count()
+	b.buf, b.err = xproto.Delete(b.buf, table.Schema, table.Name, criteria)
+	b.record("Delete", table.String(), start)
+}
+
+// Find, finds rows from table, that match the expression criteria, returning the columns named.
+// Using nil criteria matches all rows from a table, and no columns returns all columns.
+func (b *Builder) Find(table string, columns []string, criteria exprFunc) {
+	b.FindTable(Table{Name: table}, columns, criteria)
+}
+
+// FindTable is as Find, upon table, which may be schema qualified.
+func (b *Builder) FindTable(table Table, columns []string, criteria exprFunc) {
+	if b.disabled {
+		panic("Find called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	n := len(b.buf)
+
+	b.buf, b.err = xproto.Find(b.buf, table.Schema, table.Name, criteria)
+	for _, column := range columns {
+		if b.err != nil {
+			return
+		}
+		b.buf, b.err = xproto.AppendFindProjection(b.buf, Column(column), "")
+	}
+	if b.err != nil {
+		return
+	}
+	binary.LittleEndian.PutUint32(b.buf[n:], uint32(len(b.buf)-n-4))
+	b.record("Find", table.String(), start)
 }
 
 // Transaction helper
@@ -199,22 +376,22 @@ func (b *Builder) Tx(isolationLevel IsolationLevel, f func(b *Builder) error) {
 	switch isolationLevel {
 	case IsolationLevelDefault:
 	case IsolationLevelReadUncommitted:
-		b.StmtExecute("SET TRANSACTION ISOLATION LEVEL READ UNCOMMITTED")
+		b.stmtExecute("Tx", "SET TRANSACTION ISOLATION LEVEL READ UNCOMMITTED")
 	case IsolationLevelReadCommitted:
-		b.StmtExecute("SET TRANSACTION ISOLATION LEVEL READ COMMITTED")
+		b.stmtExecute("Tx", "SET TRANSACTION ISOLATION LEVEL READ COMMITTED")
 	case IsolationLevelRepeatableRead:
-		b.StmtExecute("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ")
+		b.stmtExecute("Tx", "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ")
 	case IsolationLevelSerializable:
-		b.StmtExecute("SET TRANSACTION ISOLATION LEVEL SERIALIZABLE")
+		b.stmtExecute("Tx", "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE")
 	case IsolationLevelSnapshot:
 		start = "START TRANSACTION WITH CONSISTENT SNAPSHOT"
 	default:
 		b.err = errors.New("unsupported transaction isolation level")
 		return
 	}
-	b.StmtExecute(start)
+	b.stmtExecute("Tx", start)
 	b.call(f)
-	b.StmtExecute("COMMIT")
+	b.stmtExecute("Commit", "COMMIT")
 }
 
 func (b *Builder) reset() {
@@ -223,6 +400,10 @@ func (b *Builder) reset() {
 	}
 	b.buf = b.buf[:0]
 	b.err = nil
+	b.scanned = 0
+	b.messages = 0
+	b.ops = b.ops[:0]
+	b.label = ""
 }
 
 func (b *Builder) send(ctx context.Context, s netx.Sender) ([]netx.Response, error) {
@@ -0,0 +1,65 @@
+package xtorm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/renthraysk/xtorm/netx"
+)
+
+func TestRef(t *testing.T) {
+	var refs []Ref
+
+	x := New(bufferSize)
+	x.ExpectFailOnError(OpenExpectCtxEmpty, func(b *Builder) error {
+		b.Tx(IsolationLevelDefault, func(b *Builder) error {
+			refs = append(refs, b.Ref())
+			b.Delete("foo", nil)
+			refs = append(refs, b.Ref())
+			b.Find("foo", nil, nil)
+			return nil
+		})
+		return nil
+	})
+	// Expect open, start transaction, delete, find, commit, expect close
+	if refs[0] != 2 || refs[1] != 3 || x.Ref() != 6 {
+		t.Fatalf("unexpected refs %v, %d", refs, x.Ref())
+	}
+
+	responses := make([]netx.Response, 6)
+	responses[3].ResultSets = []*netx.ResultSet{testResultSet()}
+	rows, err := refs[1].Rows(responses)
+	if err != nil {
+		t.Fatalf("failed to get rows: %s", err)
+	}
+	if rows.Len() != 2 {
+		t.Fatalf("expected 2 rows, got %d", rows.Len())
+	}
+	if _, err := refs[0].Rows(responses); err == nil {
+		t.Fatal("expected error for response without result set")
+	}
+	if _, ok := Ref(6).Response(responses); ok {
+		t.Fatal("expected no response")
+	}
+}
+
+func TestPrepareCrudOps(t *testing.T) {
+	var ref Ref
+
+	x := New(bufferSize)
+	x.PrepareCrud(1, func(b *Builder) error {
+		b.Find("foo", nil, Eq(Column("id"), Placeholder(0)))
+		return nil
+	})
+	x.Execute(1, 42)
+	ref = x.Ref()
+	x.Deallocate(1)
+
+	expected := []string{"PrepareFind 0-1", "Execute 1-2", "Deallocate 2-3"}
+	if ranges := testOpRanges(t, &x.Builder); !reflect.DeepEqual(ranges, expected) {
+		t.Fatalf("expected ops %v, got %v", expected, ranges)
+	}
+	if ref != 2 || x.Ref() != 3 {
+		t.Fatalf("unexpected refs %d, %d", ref, x.Ref())
+	}
+}
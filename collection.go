@@ -0,0 +1,222 @@
+package xtorm
+
+import (
+	"encoding/binary"
+	encjson "encoding/json"
+	"fmt"
+
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+// Collection appends document store operations upon a collection of JSON documents.
+// Fields of documents are referred to in criteria via Field() or Path().
+type Collection struct {
+	b     *Builder
+	table Table
+}
+
+// Collection returns the document store operations of collection table.
+func (b *Builder) Collection(table Table) *Collection {
+	if b.disabled {
+		panic("Collection called on non child")
+	}
+	return &Collection{b: b, table: table}
+}
+
+// Add adds documents to the collection. Documents may be json.RawMessage, JSON(), JSONString() values, or any value
+// that can be marshalled with encoding/json. Documents without an _id are assigned one by the server, which are
+// returned in the Response's GeneratedDocumentIDs.
+func (c *Collection) Add(docs ...interface{}) {
+	c.b.insertDocuments("Add", c.table, docs, false)
+}
+
+// AddOrReplace adds documents to the collection, replacing existing documents with the same _id.
+func (c *Collection) AddOrReplace(docs ...interface{}) {
+	c.b.insertDocuments("AddOrReplace", c.table, docs, true)
+}
+
+// Modify modifies the documents that match the expression criteria, with f appending the modifications.
+// Using nil criteria modifies all documents.
+func (c *Collection) Modify(criteria exprFunc, f func(m *Modify) error) {
+	b := c.b
+	if b.disabled {
+		panic("Modify called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	n := len(b.buf)
+	var m Modify
+	m.buf, m.err = xproto.UpdateDocuments(b.buf, c.table.Schema, c.table.Name, criteria)
+	b.disabled = true
+	if m.err == nil {
+		m.err = f(&m)
+	}
+	b.buf, b.err = m.buf, m.err
+	binary.LittleEndian.PutUint32(b.buf[n:], uint32(len(b.buf)-n-4))
+	b.record("Modify", c.table.String(), start)
+	b.disabled = false
+}
+
+// Remove removes the documents that match the expression criteria. Using nil criteria removes all documents.
+func (c *Collection) Remove(criteria exprFunc) {
+	b := c.b
+	if b.disabled {
+		panic("Remove called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	b.buf, b.err = xproto.DeleteDocuments(b.buf, c.table.Schema, c.table.Name, criteria)
+	b.record("Remove", c.table.String(), start)
+}
+
+// Find finds the documents that match the expression criteria, returned as a result set of a single doc column.
+// Using nil criteria matches all documents.
+func (c *Collection) Find(criteria exprFunc) {
+	c.FindF(criteria, func(f *Find) error { return nil })
+}
+
+// FindF finds the documents that match the expression criteria, with f adding ordering, limits etc.
+func (c *Collection) FindF(criteria exprFunc, f func(f *Find) error) {
+	b := c.b
+	if b.disabled {
+		panic("Find called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	n := len(b.buf)
+	var fi Find
+	fi.buf, fi.err = xproto.FindDocuments(b.buf, c.table.Schema, c.table.Name, criteria)
+	b.disabled = true
+	if fi.err == nil {
+		fi.err = f(&fi)
+	}
+	b.buf, b.err = fi.buf, fi.err
+	binary.LittleEndian.PutUint32(b.buf[n:], uint32(len(b.buf)-n-4))
+	b.record("Find", c.table.String(), start)
+	b.disabled = false
+}
+
+func (b *Builder) insertDocuments(kind string, table Table, docs []interface{}, upsert bool) {
+	if b.disabled {
+		panic(kind + " called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	n := len(b.buf)
+
+	b.buf = xproto.InsertDocuments(b.buf, table.Schema, table.Name)
+	for i, doc := range docs {
+		d, err := document(doc)
+		if err == nil {
+			b.buf, err = xproto.AppendInsertRow(b.buf, []interface{}{d})
+		}
+		if err != nil {
+			b.err = fmt.Errorf("document %d: %w", i, err)
+			return
+		}
+	}
+	if upsert {
+		b.buf = xproto.AppendInsertUpsert(b.buf)
+	}
+	binary.LittleEndian.PutUint32(b.buf[n:], uint32(len(b.buf)-n-4))
+	b.record(kind, table.String(), start)
+}
+
+// document returns v as a JSON document expression, marshalling it if not already JSON.
+func document(v interface{}) (interface{}, error) {
+	switch d := v.(type) {
+	case json, jsonString:
+		return d, nil
+	case encjson.RawMessage:
+		return JSON(d), nil
+	case []byte:
+		return JSON(d), nil
+	case string:
+		return JSONString(d), nil
+	}
+	b, err := encjson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return JSON(b), nil
+}
+
+// Modify appends modifications of documents to a Collection.Modify.
+type Modify struct {
+	buf []byte
+	err error
+}
+
+func (m *Modify) operation(op xproto.UpdateType, path DocumentPath, value interface{}) {
+	if m.err == nil {
+		m.buf, m.err = xproto.AppendUpdateOperation(m.buf, op, "", path, value)
+	}
+}
+
+// Set sets the field at path to value, adding it if not present.
+func (m *Modify) Set(path DocumentPath, value interface{}) {
+	m.operation(xproto.UpdateItemSet, path, value)
+}
+
+// Unset removes the field at path.
+func (m *Modify) Unset(path DocumentPath) {
+	m.operation(xproto.UpdateItemRemove, path, nil)
+}
+
+// Replace replaces the field at path with value, only if already present.
+func (m *Modify) Replace(path DocumentPath, value interface{}) {
+	m.operation(xproto.UpdateItemReplace, path, value)
+}
+
+// ArrayAppend appends value to the array at path.
+func (m *Modify) ArrayAppend(path DocumentPath, value interface{}) {
+	m.operation(xproto.UpdateArrayAppend, path, value)
+}
+
+// ArrayInsert inserts value into an array, at the position given by path, which must end with an Index().
+func (m *Modify) ArrayInsert(path DocumentPath, value interface{}) {
+	m.operation(xproto.UpdateArrayInsert, path, value)
+}
+
+// Patch merges the document patch into the documents, as with JSON_MERGE_PATCH(). The patch may be any value
+// accepted by Collection.Add().
+func (m *Modify) Patch(patch interface{}) {
+	if m.err != nil {
+		return
+	}
+	d, err := document(patch)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.operation(xproto.UpdateMergePatch, nil, d)
+}
+
+// OrderBy modifies documents in ascending order of the expression expr.
+func (m *Modify) OrderBy(expr interface{}) {
+	if m.err == nil {
+		m.buf, m.err = xproto.AppendUpdateOrder(m.buf, expr, false)
+	}
+}
+
+// OrderByDesc modifies documents in descending order of the expression expr.
+func (m *Modify) OrderByDesc(expr interface{}) {
+	if m.err == nil {
+		m.buf, m.err = xproto.AppendUpdateOrder(m.buf, expr, true)
+	}
+}
+
+// Limit modifies at most rowCount documents.
+func (m *Modify) Limit(rowCount uint64) {
+	if m.err == nil {
+		m.buf = xproto.AppendUpdateLimit(m.buf, rowCount)
+	}
+}
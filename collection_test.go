@@ -0,0 +1,114 @@
+package xtorm
+
+import (
+	encjson "encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_crud"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_datatypes"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_sql"
+)
+
+func TestCollection(t *testing.T) {
+	type order struct {
+		ID     string `json:"_id"`
+		Status string `json:"status"`
+	}
+	orders := Table{Schema: "shop", Name: "orders"}
+
+	x := New(bufferSize)
+	x.ExpectFailOnError(OpenExpectCtxEmpty, func(b *Builder) error {
+		b.CreateCollection(orders)
+		c := b.Collection(orders)
+		c.Add(order{ID: "1", Status: "new"}, encjson.RawMessage(`{"status": "new"}`))
+		c.Modify(Eq(Field("status"), "new"), func(m *Modify) error {
+			m.Set(Field("status"), "paid")
+			m.Unset(Field("address.city"))
+			m.Patch(map[string]string{"paid": "yes"})
+			return nil
+		})
+		c.Find(Eq(Field("status"), "paid"))
+		c.Remove(Eq(Field("_id"), "1"))
+		b.DropCollection(orders)
+		return nil
+	})
+	if x.err != nil {
+		t.Fatalf("failed to build: %s", x.err)
+	}
+	ops := x.Ops()
+	kinds := make([]string, len(ops))
+	for i, op := range ops {
+		kinds[i] = op.Kind
+	}
+	expected := []string{"ExpectOpen", "CreateCollection", "Add", "Modify", "Find", "Remove", "DropCollection", "ExpectClose"}
+	if len(kinds) != len(expected) {
+		t.Fatalf("unexpected ops %v", kinds)
+	}
+	for i := range expected {
+		if kinds[i] != expected[i] {
+			t.Fatalf("unexpected ops %v", kinds)
+		}
+	}
+
+	var s mysqlx_sql.StmtExecute
+	if err := proto.Unmarshal(testMessage(x.buf, 1)[5:], &s); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if string(s.GetStmt()) != "create_collection" || s.GetNamespace() != "mysqlx" || len(s.GetArgs()) != 1 ||
+		s.GetArgs()[0].GetType() != mysqlx_datatypes.Any_OBJECT {
+		t.Fatalf("unexpected admin command %v", &s)
+	}
+	fields := s.GetArgs()[0].GetObj().GetFld()
+	if len(fields) != 2 || fields[0].GetKey() != "schema" || string(fields[0].GetValue().GetScalar().GetVString().GetValue()) != "shop" {
+		t.Fatalf("unexpected admin command arguments %v", fields)
+	}
+
+	var i mysqlx_crud.Insert
+	if err := proto.Unmarshal(testMessage(x.buf, 2)[5:], &i); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if i.GetDataModel() != mysqlx_crud.DataModel_DOCUMENT || len(i.GetRow()) != 2 || i.GetUpsert() {
+		t.Fatalf("unexpected add %v", &i)
+	}
+	if doc := string(i.GetRow()[0].GetField()[0].GetLiteral().GetVOctets().GetValue()); doc != `{"_id":"1","status":"new"}` {
+		t.Fatalf("unexpected document %s", doc)
+	}
+
+	var u mysqlx_crud.Update
+	if err := proto.Unmarshal(testMessage(x.buf, 3)[5:], &u); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if u.GetDataModel() != mysqlx_crud.DataModel_DOCUMENT || len(u.GetOperation()) != 3 {
+		t.Fatalf("unexpected modify %v", &u)
+	}
+	if path := u.GetOperation()[1].GetSource().GetDocumentPath(); len(path) != 2 || path[1].GetValue() != "city" {
+		t.Fatalf("unexpected document path %v", path)
+	}
+	if u.GetCriteria().GetOperator().GetParam()[0].GetIdentifier().GetDocumentPath()[0].GetValue() != "status" {
+		t.Fatalf("unexpected criteria %v", u.GetCriteria())
+	}
+
+	var f mysqlx_crud.Find
+	if err := proto.Unmarshal(testMessage(x.buf, 4)[5:], &f); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if f.GetDataModel() != mysqlx_crud.DataModel_DOCUMENT || f.GetCollection().GetSchema() != "shop" {
+		t.Fatalf("unexpected find %v", &f)
+	}
+
+	var d mysqlx_crud.Delete
+	if err := proto.Unmarshal(testMessage(x.buf, 5)[5:], &d); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if d.GetDataModel() != mysqlx_crud.DataModel_DOCUMENT || d.GetCollection().GetName() != "orders" {
+		t.Fatalf("unexpected remove %v", &d)
+	}
+
+	x = New(bufferSize)
+	x.CreateCollection(Table{Name: "orders"})
+	if x.err == nil {
+		t.Fatal("expected error creating collection without schema")
+	}
+}
@@ -0,0 +1,259 @@
+package xtorm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	encjson "encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/renthraysk/xtorm/types"
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+var (
+	scannerType  = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	decimalType  = reflect.TypeOf(types.Decimal(nil))
+)
+
+// assign stores the value v, as returned from xdecode.Field(), into the value pointed at by dest.
+func assign(dest interface{}, v interface{}) error {
+	switch d := dest.(type) {
+	case *interface{}:
+		*d = v
+		return nil
+	case sql.Scanner:
+		return d.Scan(driverValue(v))
+	}
+
+	switch s := v.(type) {
+	case int64:
+		switch d := dest.(type) {
+		case *int64:
+			*d = s
+			return nil
+		case *int:
+			if int64(int(s)) == s {
+				*d = int(s)
+				return nil
+			}
+		}
+	case uint64:
+		switch d := dest.(type) {
+		case *uint64:
+			*d = s
+			return nil
+		case *uint:
+			if uint64(uint(s)) == s {
+				*d = uint(s)
+				return nil
+			}
+		}
+	case float64:
+		if d, ok := dest.(*float64); ok {
+			*d = s
+			return nil
+		}
+	case string:
+		if d, ok := dest.(*string); ok {
+			*d = s
+			return nil
+		}
+	case []byte:
+		if d, ok := dest.(*[]byte); ok {
+			*d = append(make([]byte, 0, len(s)), s...)
+			return nil
+		}
+	case time.Time:
+		if d, ok := dest.(*time.Time); ok {
+			*d = s
+			return nil
+		}
+	}
+
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("destination not a non nil pointer, got %T", dest)
+	}
+	return assignValue(dv.Elem(), v)
+}
+
+func assignValue(dv reflect.Value, v interface{}) error {
+	if v == nil {
+		switch dv.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			dv.Set(reflect.Zero(dv.Type()))
+			return nil
+		}
+		return fmt.Errorf("cannot scan NULL into %s", dv.Type())
+	}
+	if dv.Kind() == reflect.Ptr {
+		e := reflect.New(dv.Type().Elem())
+		if s, ok := e.Interface().(sql.Scanner); ok {
+			if err := s.Scan(driverValue(v)); err != nil {
+				return err
+			}
+		} else if err := assignValue(e.Elem(), v); err != nil {
+			return err
+		}
+		dv.Set(e)
+		return nil
+	}
+	if dv.Kind() == reflect.Interface {
+		dv.Set(reflect.ValueOf(v))
+		return nil
+	}
+
+	sv := reflect.ValueOf(v)
+	switch dv.Type() {
+	case timeType, durationType, decimalType:
+		if sv.Type() == dv.Type() {
+			dv.Set(sv)
+			return nil
+		}
+		return fmt.Errorf("cannot scan %T into %s", v, dv.Type())
+	}
+
+	switch dv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var x int64
+		switch s := v.(type) {
+		case int64:
+			x = s
+		case uint64:
+			if s > math.MaxInt64 {
+				return fmt.Errorf("value %d overflows %s", s, dv.Type())
+			}
+			x = int64(s)
+		default:
+			return fmt.Errorf("cannot scan %T into %s", v, dv.Type())
+		}
+		if dv.OverflowInt(x) {
+			return fmt.Errorf("value %d overflows %s", x, dv.Type())
+		}
+		dv.SetInt(x)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var x uint64
+		switch s := v.(type) {
+		case uint64:
+			x = s
+		case int64:
+			if s < 0 {
+				return fmt.Errorf("value %d overflows %s", s, dv.Type())
+			}
+			x = uint64(s)
+		default:
+			return fmt.Errorf("cannot scan %T into %s", v, dv.Type())
+		}
+		if dv.OverflowUint(x) {
+			return fmt.Errorf("value %d overflows %s", x, dv.Type())
+		}
+		dv.SetUint(x)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		switch s := v.(type) {
+		case float64:
+			dv.SetFloat(s)
+		case int64:
+			dv.SetFloat(float64(s))
+		case uint64:
+			dv.SetFloat(float64(s))
+		case types.Decimal:
+			f, err := strconv.ParseFloat(s.String(), dv.Type().Bits())
+			if err != nil {
+				return err
+			}
+			dv.SetFloat(f)
+		default:
+			return fmt.Errorf("cannot scan %T into %s", v, dv.Type())
+		}
+		return nil
+
+	case reflect.Bool:
+		switch s := v.(type) {
+		case int64:
+			dv.SetBool(s != 0)
+		case uint64:
+			dv.SetBool(s != 0)
+		default:
+			return fmt.Errorf("cannot scan %T into %s", v, dv.Type())
+		}
+		return nil
+
+	case reflect.String:
+		switch s := v.(type) {
+		case string:
+			dv.SetString(s)
+		case []byte:
+			dv.SetString(string(s))
+		case encjson.RawMessage:
+			dv.SetString(string(s))
+		case types.Decimal:
+			dv.SetString(s.String())
+		case []string:
+			dv.SetString(strings.Join(s, ","))
+		default:
+			return fmt.Errorf("cannot scan %T into %s", v, dv.Type())
+		}
+		return nil
+
+	case reflect.Slice:
+		switch dv.Type().Elem().Kind() {
+		case reflect.Uint8:
+			var b []byte
+			switch s := v.(type) {
+			case []byte:
+				b = s
+			case encjson.RawMessage:
+				b = s
+			case string:
+				b = []byte(s)
+			default:
+				return fmt.Errorf("cannot scan %T into %s", v, dv.Type())
+			}
+			dv.SetBytes(append(make([]byte, 0, len(b)), b...))
+			return nil
+		case reflect.String:
+			s, ok := v.([]string)
+			if !ok {
+				return fmt.Errorf("cannot scan %T into %s", v, dv.Type())
+			}
+			ss := reflect.MakeSlice(dv.Type(), len(s), len(s))
+			for i, e := range s {
+				ss.Index(i).SetString(e)
+			}
+			dv.Set(ss)
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot scan %T into %s", v, dv.Type())
+}
+
+// driverValue converts a value returned from xdecode.Field() into a driver.Value, for sql.Scanner implementations.
+func driverValue(v interface{}) driver.Value {
+	switch s := v.(type) {
+	case uint64:
+		if s <= math.MaxInt64 {
+			return int64(s)
+		}
+		return strconv.FormatUint(s, 10)
+	case encjson.RawMessage:
+		return []byte(s)
+	case time.Duration:
+		return string(xproto.AppendDuration(nil, s))
+	case types.Decimal:
+		return s.String()
+	case []string:
+		return strings.Join(s, ",")
+	}
+	return v
+}
@@ -21,19 +21,26 @@ func (i *Insert) RowV(args ...interface{}) {
 	i.Row(args)
 }
 
-func (b *Builder) InsertF(name string, columns []string, f func(i *Insert) error) {
+func (b *Builder) InsertF(table string, columns []string, f func(i *Insert) error) {
+	b.InsertTableF(Table{Name: table}, columns, f)
+}
+
+// InsertTableF is as InsertF, upon table, which may be schema qualified.
+func (b *Builder) InsertTableF(table Table, columns []string, f func(i *Insert) error) {
 	if b.disabled {
 		panic("Insert called on non child")
 	}
 	if b.err != nil {
 		return
 	}
+	start := b.count()
 	n := len(b.buf)
-	i := &Insert{buf: xproto.Insert(b.buf, name, columns)}
+	i := &Insert{buf: xproto.Insert(b.buf, table.Schema, table.Name, columns)}
 	b.disabled = true
 	f(i)
 	b.buf, b.err = i.buf, i.err
 	binary.LittleEndian.PutUint32(b.buf[n:], uint32(len(b.buf)-n-4))
+	b.record("Insert", table.String(), start)
 	b.disabled = false
 }
 
@@ -48,19 +55,267 @@ func (u *Update) Set(name string, value interface{}) {
 	}
 }
 
-func (b *Builder) UpdateF(name string, criteria exprFunc, f func(u *Update) error) {
+func (u *Update) operation(op xproto.UpdateType, column string, path DocumentPath, value interface{}) {
+	if u.err == nil {
+		u.buf, u.err = xproto.AppendUpdateOperation(u.buf, op, column, path, value)
+	}
+}
+
+// ItemSet sets the item at path within the JSON column to value, adding it if not present.
+func (u *Update) ItemSet(column string, path DocumentPath, value interface{}) {
+	u.operation(xproto.UpdateItemSet, column, path, value)
+}
+
+// ItemRemove removes the item at path within the JSON column.
+func (u *Update) ItemRemove(column string, path DocumentPath) {
+	u.operation(xproto.UpdateItemRemove, column, path, nil)
+}
+
+// ItemReplace replaces the item at path within the JSON column with value, only if already present.
+func (u *Update) ItemReplace(column string, path DocumentPath, value interface{}) {
+	u.operation(xproto.UpdateItemReplace, column, path, value)
+}
+
+// ArrayAppend appends value to the array at path within the JSON column.
+func (u *Update) ArrayAppend(column string, path DocumentPath, value interface{}) {
+	u.operation(xproto.UpdateArrayAppend, column, path, value)
+}
+
+// ArrayInsert inserts value into an array within the JSON column, at the position given by path, which must end with
+// an Index().
+func (u *Update) ArrayInsert(column string, path DocumentPath, value interface{}) {
+	u.operation(xproto.UpdateArrayInsert, column, path, value)
+}
+
+// MergePatch merges the JSON document patch into the JSON column, as with JSON_MERGE_PATCH().
+func (u *Update) MergePatch(column string, patch interface{}) {
+	u.operation(xproto.UpdateMergePatch, column, nil, patch)
+}
+
+// OrderBy updates rows in ascending order of the expression expr.
+func (u *Update) OrderBy(expr interface{}) {
+	if u.err == nil {
+		u.buf, u.err = xproto.AppendUpdateOrder(u.buf, expr, false)
+	}
+}
+
+// OrderByDesc updates rows in descending order of the expression expr.
+func (u *Update) OrderByDesc(expr interface{}) {
+	if u.err == nil {
+		u.buf, u.err = xproto.AppendUpdateOrder(u.buf, expr, true)
+	}
+}
+
+// Limit updates at most rowCount rows.
+func (u *Update) Limit(rowCount uint64) {
+	if u.err == nil {
+		u.buf = xproto.AppendUpdateLimit(u.buf, rowCount)
+	}
+}
+
+// LimitExpr updates at most the expression rowCount rows, eg a Placeholder() in a prepared update.
+func (u *Update) LimitExpr(rowCount interface{}) {
+	if u.err == nil {
+		u.buf, u.err = xproto.AppendUpdateLimitExpr(u.buf, rowCount)
+	}
+}
+
+func (b *Builder) UpdateF(table string, criteria exprFunc, f func(u *Update) error) {
+	b.UpdateTableF(Table{Name: table}, criteria, f)
+}
+
+// UpdateTableF is as UpdateF, upon table, which may be schema qualified.
+func (b *Builder) UpdateTableF(table Table, criteria exprFunc, f func(u *Update) error) {
 	if b.disabled {
 		panic("Update called on non child")
 	}
 	if b.err != nil {
 		return
 	}
+	start := b.count()
 	n := len(b.buf)
 	var u Update
-	u.buf, u.err = xproto.Update(b.buf, name, criteria)
+	u.buf, u.err = xproto.Update(b.buf, table.Schema, table.Name, criteria)
 	b.disabled = true
 	f(&u)
 	b.buf, b.err = u.buf, u.err
 	binary.LittleEndian.PutUint32(b.buf[n:], uint32(len(b.buf)-n-4))
+	b.record("Update", table.String(), start)
+	b.disabled = false
+}
+
+type Delete struct {
+	buf []byte
+	err error
+}
+
+// OrderBy deletes rows in ascending order of the expression expr.
+func (d *Delete) OrderBy(expr interface{}) {
+	if d.err == nil {
+		d.buf, d.err = xproto.AppendDeleteOrder(d.buf, expr, false)
+	}
+}
+
+// OrderByDesc deletes rows in descending order of the expression expr.
+func (d *Delete) OrderByDesc(expr interface{}) {
+	if d.err == nil {
+		d.buf, d.err = xproto.AppendDeleteOrder(d.buf, expr, true)
+	}
+}
+
+// Limit deletes at most rowCount rows.
+func (d *Delete) Limit(rowCount uint64) {
+	if d.err == nil {
+		d.buf = xproto.AppendDeleteLimit(d.buf, rowCount)
+	}
+}
+
+// LimitExpr deletes at most the expression rowCount rows, eg a Placeholder() in a prepared delete.
+func (d *Delete) LimitExpr(rowCount interface{}) {
+	if d.err == nil {
+		d.buf, d.err = xproto.AppendDeleteLimitExpr(d.buf, rowCount)
+	}
+}
+
+// DeleteF deletes rows from table that match the expression criteria, with f adding ordering and limits, eg to
+// delete the oldest rows in bounded batches. Using nil criteria matches all rows.
+func (b *Builder) DeleteF(table string, criteria exprFunc, f func(d *Delete) error) {
+	b.DeleteTableF(Table{Name: table}, criteria, f)
+}
+
+// DeleteTableF is as DeleteF, upon table, which may be schema qualified.
+func (b *Builder) DeleteTableF(table Table, criteria exprFunc, f func(d *Delete) error) {
+	if b.disabled {
+		panic("Delete called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	n := len(b.buf)
+	var d Delete
+	d.buf, d.err = xproto.Delete(b.buf, table.Schema, table.Name, criteria)
+	b.disabled = true
+	if d.err == nil {
+		d.err = f(&d)
+	}
+	b.buf, b.err = d.buf, d.err
+	binary.LittleEndian.PutUint32(b.buf[n:], uint32(len(b.buf)-n-4))
+	b.record("Delete", table.String(), start)
+	b.disabled = false
+}
+
+type Find struct {
+	buf []byte
+	err error
+}
+
+// Column adds the column name to the projection.
+func (f *Find) Column(name string) {
+	f.Project(Column(name), "")
+}
+
+// Project adds the expression expr to the projection, with an optional alias.
+func (f *Find) Project(expr interface{}, alias string) {
+	if f.err == nil {
+		f.buf, f.err = xproto.AppendFindProjection(f.buf, expr, alias)
+	}
+}
+
+// OrderBy sorts rows by the expression expr in ascending order.
+func (f *Find) OrderBy(expr interface{}) {
+	if f.err == nil {
+		f.buf, f.err = xproto.AppendFindOrder(f.buf, expr, false)
+	}
+}
+
+// OrderByDesc sorts rows by the expression expr in descending order.
+func (f *Find) OrderByDesc(expr interface{}) {
+	if f.err == nil {
+		f.buf, f.err = xproto.AppendFindOrder(f.buf, expr, true)
+	}
+}
+
+// GroupBy groups rows by the expression expr.
+func (f *Find) GroupBy(expr interface{}) {
+	if f.err == nil {
+		f.buf, f.err = xproto.AppendFindGrouping(f.buf, expr)
+	}
+}
+
+// Having filters the grouped rows by criteria.
+func (f *Find) Having(criteria exprFunc) {
+	if f.err == nil {
+		f.buf, f.err = xproto.AppendFindGroupingCriteria(f.buf, criteria)
+	}
+}
+
+// Limit returns at most rowCount rows, after skipping offset rows.
+func (f *Find) Limit(rowCount, offset uint64) {
+	if f.err == nil {
+		f.buf = xproto.AppendFindLimit(f.buf, rowCount, offset)
+	}
+}
+
+// LimitExpr returns at most the expression rowCount rows, after skipping the expression offset rows, eg Placeholder()s
+// in a prepared find. A nil offset skips no rows.
+func (f *Find) LimitExpr(rowCount, offset interface{}) {
+	if f.err == nil {
+		f.buf, f.err = xproto.AppendFindLimitExpr(f.buf, rowCount, offset)
+	}
+}
+
+type RowLockOptions uint8
+
+const (
+	// Wait for locked rows to be released, the default.
+	Wait = RowLockOptions(xproto.RowLockWait)
+	// NoWait fails immediately if any row is locked.
+	NoWait = RowLockOptions(xproto.RowLockNoWait)
+	// SkipLocked removes locked rows from the result set.
+	SkipLocked = RowLockOptions(xproto.RowLockSkipLocked)
+)
+
+// ForUpdate takes an exclusive lock on the rows read, as with SELECT ... FOR UPDATE.
+// Locks are only held for the duration of a transaction, so should be used within a Tx block.
+func (f *Find) ForUpdate(options RowLockOptions) {
+	if f.err == nil {
+		f.buf = xproto.AppendFindLocking(f.buf, xproto.RowLockExclusive, xproto.RowLockOptions(options))
+	}
+}
+
+// ForShare takes a shared lock on the rows read, as with SELECT ... FOR SHARE.
+// Locks are only held for the duration of a transaction, so should be used within a Tx block.
+func (f *Find) ForShare(options RowLockOptions) {
+	if f.err == nil {
+		f.buf = xproto.AppendFindLocking(f.buf, xproto.RowLockShared, xproto.RowLockOptions(options))
+	}
+}
+
+// FindF finds rows in table that match the expression criteria, with f adding projections, ordering,
+// grouping and limits. Using nil criteria matches all rows, and no projection returns all columns.
+func (b *Builder) FindF(table string, criteria exprFunc, f func(f *Find) error) {
+	b.FindTableF(Table{Name: table}, criteria, f)
+}
+
+// FindTableF is as FindF, upon table, which may be schema qualified.
+func (b *Builder) FindTableF(table Table, criteria exprFunc, f func(f *Find) error) {
+	if b.disabled {
+		panic("Find called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	n := len(b.buf)
+	var fi Find
+	fi.buf, fi.err = xproto.Find(b.buf, table.Schema, table.Name, criteria)
+	b.disabled = true
+	if fi.err == nil {
+		fi.err = f(&fi)
+	}
+	b.buf, b.err = fi.buf, fi.err
+	binary.LittleEndian.PutUint32(b.buf[n:], uint32(len(b.buf)-n-4))
+	b.record("Find", table.String(), start)
 	b.disabled = false
 }
@@ -0,0 +1,119 @@
+package xtorm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/renthraysk/xtorm/netx"
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+// Cursor streams the rows of a prepared statement execution, fetching them from the server in batches on demand, so
+// only a single batch is held in memory.
+// Cursors are session state, so must be used with a netx.Conn, on which the statement was prepared, not a pool.
+type Cursor struct {
+	conn  netx.Sender
+	id    uint32
+	batch uint64
+	buf   []byte
+
+	rows *Rows
+	done bool
+	err  error
+}
+
+// OpenCursor opens a cursor with cursorID, over the execution of the statement prepared with stmtID with args,
+// fetching batch rows at a time.
+func OpenCursor(ctx context.Context, conn netx.Conn, cursorID, stmtID uint32, batch uint64, args ...interface{}) (*Cursor, error) {
+	if batch == 0 {
+		return nil, errors.New("cursor batch size must be greater than 0")
+	}
+	c := &Cursor{conn: conn, id: cursorID, batch: batch}
+	b, err := xproto.CursorOpen(nil, cursorID, stmtID, args, batch)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := c.send(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		return nil, errors.New("no result set opening cursor")
+	}
+	c.rows = NewRows(rs)
+	c.done = !rs.Suspended
+	return c, nil
+}
+
+func (c *Cursor) send(ctx context.Context, b []byte) (*netx.ResultSet, error) {
+	c.buf = b
+	r, err := c.conn.Send(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) != 1 {
+		return nil, errors.New("expected a single response")
+	}
+	if r[0].Err != nil {
+		return nil, r[0].Err
+	}
+	if len(r[0].ResultSets) == 0 {
+		return nil, nil
+	}
+	return r[0].ResultSets[0], nil
+}
+
+// Columns returns the names of the columns of the result set.
+func (c *Cursor) Columns() []string {
+	return c.rows.Columns()
+}
+
+// Next advances to the next row, fetching the next batch of rows from the server when the current is exhausted.
+// Returns false when there are no more rows, or an error occurred, which is returned by Err().
+func (c *Cursor) Next(ctx context.Context) bool {
+	if c.err != nil {
+		return false
+	}
+	if c.rows.Next() {
+		return true
+	}
+	if c.done {
+		return false
+	}
+	rs, err := c.send(ctx, xproto.CursorFetch(c.buf[:0], c.id, c.batch))
+	if err != nil {
+		c.err = err
+		return false
+	}
+	if rs == nil {
+		c.done = true
+		return false
+	}
+	c.done = !rs.Suspended
+	// Fetched rows are not preceded by ColumnMetaData, so continue with those from opening.
+	rs.Columns = c.rows.rs.Columns
+	c.rows.rs = rs
+	c.rows.row = -1
+	return c.rows.Next()
+}
+
+// Err returns the error, if any, that occurred fetching rows.
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Scan decodes the columns of the current row into the values pointed at by dest, as with Rows.Scan().
+func (c *Cursor) Scan(dest ...interface{}) error {
+	return c.rows.Scan(dest...)
+}
+
+// ScanStruct decodes the current row into the struct pointed to by v, as with Rows.ScanStruct().
+func (c *Cursor) ScanStruct(v interface{}) error {
+	return c.rows.ScanStruct(v)
+}
+
+// Close closes the cursor, releasing it on the server.
+func (c *Cursor) Close(ctx context.Context) error {
+	_, err := c.send(ctx, xproto.CursorClose(c.buf[:0], c.id))
+	return err
+}
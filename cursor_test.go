@@ -0,0 +1,66 @@
+package xtorm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/renthraysk/xtorm/netx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+)
+
+// fakeConn responds to each Send with the next of responses.
+type fakeConn struct {
+	responses [][]netx.Response
+	sent      []mysqlx.ClientMessages_Type
+}
+
+func (c *fakeConn) Send(ctx context.Context, b []byte) ([]netx.Response, error) {
+	c.sent = append(c.sent, mysqlx.ClientMessages_Type(b[4]))
+	r := c.responses[0]
+	c.responses = c.responses[1:]
+	return r, nil
+}
+
+func (c *fakeConn) Close() error                           { return nil }
+func (c *fakeConn) CloseContext(ctx context.Context) error { return nil }
+func (c *fakeConn) IsSecure() bool                         { return true }
+
+func (c *fakeConn) Capabilities() netx.Capabilities { return netx.Capabilities{} }
+
+func TestCursor(t *testing.T) {
+	first := testResultSet()
+	first.Suspended = true
+	second := &netx.ResultSet{Rows: []netx.Row{{testSint(3), testString("carol"), nil}}}
+
+	conn := &fakeConn{responses: [][]netx.Response{
+		{{ResultSets: []*netx.ResultSet{first}}},
+		{{ResultSets: []*netx.ResultSet{second}}},
+		{{}},
+	}}
+	c, err := OpenCursor(context.Background(), conn, 1, 1, 2)
+	if err != nil {
+		t.Fatalf("failed to open cursor: %s", err)
+	}
+	var names []string
+	for c.Next(context.Background()) {
+		var u testUser
+		if err := c.ScanStruct(&u); err != nil {
+			t.Fatalf("failed to scan: %s", err)
+		}
+		names = append(names, u.Name)
+	}
+	if err := c.Err(); err != nil {
+		t.Fatalf("failed to fetch: %s", err)
+	}
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	if !reflect.DeepEqual(names, []string{"alice", "bob", "carol"}) {
+		t.Fatalf("unexpected rows %v", names)
+	}
+	if !reflect.DeepEqual(conn.sent, []mysqlx.ClientMessages_Type{mysqlx.ClientMessages_CURSOR_OPEN,
+		mysqlx.ClientMessages_CURSOR_FETCH, mysqlx.ClientMessages_CURSOR_CLOSE}) {
+		t.Fatalf("unexpected messages sent %v", conn.sent)
+	}
+}
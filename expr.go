@@ -91,6 +91,13 @@ func Column(name string) exprFunc {
 	}
 }
 
+// Placeholder returns a placeholder for the argument at position pos, supplied when executing a prepared statement.
+func Placeholder(pos uint32) exprFunc {
+	return func(p []byte, tag uint8) ([]byte, error) {
+		return xproto.AppendExprPlaceholder(p, tag, pos)
+	}
+}
+
 // Operations
 
 func Default() exprFunc {
@@ -0,0 +1,81 @@
+package xtorm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/collation"
+	"github.com/renthraysk/xtorm/netx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_resultset"
+)
+
+// testMessage returns the i'th message framed in buf.
+func testMessage(buf []byte, i int) []byte {
+	for ; i > 0; i-- {
+		buf = buf[4+binary.LittleEndian.Uint32(buf):]
+	}
+	return buf[:4+binary.LittleEndian.Uint32(buf)]
+}
+
+func testColumn(name string, t mysqlx_resultset.ColumnMetaData_FieldType) *mysqlx_resultset.ColumnMetaData {
+	c := &mysqlx_resultset.ColumnMetaData{Type: &t, Name: []byte(name)}
+	if t == mysqlx_resultset.ColumnMetaData_BYTES {
+		c.Collation = proto.Uint64(uint64(collation.UTF8mb40900AiCi))
+	}
+	return c
+}
+
+func testSint(v int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return b[:binary.PutUvarint(b[:], uint64(v)<<1^uint64(v>>63))]
+}
+
+func testString(s string) []byte {
+	return append([]byte(s), 0)
+}
+
+func testResultSet() *netx.ResultSet {
+	return &netx.ResultSet{
+		Columns: []*mysqlx_resultset.ColumnMetaData{
+			testColumn("id", mysqlx_resultset.ColumnMetaData_SINT),
+			testColumn("name", mysqlx_resultset.ColumnMetaData_BYTES),
+			testColumn("nickname", mysqlx_resultset.ColumnMetaData_BYTES),
+		},
+		Rows: []netx.Row{
+			{testSint(1), testString("alice"), nil},
+			{testSint(2), testString("bob"), testString("bobby")},
+		},
+	}
+}
+
+type testBase struct {
+	ID int32 `xtorm:"id"`
+}
+
+type testUser struct {
+	testBase
+	Name     string
+	Nickname *string `xtorm:"nickname"`
+	Ignored  string  `xtorm:"-"`
+}
+
+// testOpRanges returns the kind and message range of each operation of x, checking the messages counted match those
+// within its buffer.
+func testOpRanges(t *testing.T, x *Builder) []string {
+	t.Helper()
+	n := 0
+	for i := 0; i < len(x.buf); i += 4 + int(binary.LittleEndian.Uint32(x.buf[i:])) {
+		n++
+	}
+	if x.count() != n {
+		t.Fatalf("counted %d messages, buffer has %d", x.count(), n)
+	}
+	ranges := make([]string, len(x.Ops()))
+	for i, op := range x.Ops() {
+		ranges[i] = fmt.Sprintf("%s %d-%d", op.Kind, op.Start, op.End)
+	}
+	return ranges
+}
@@ -0,0 +1,95 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/renthraysk/xtorm/netx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_connection"
+	"github.com/renthraysk/xtorm/xdecode"
+	"github.com/renthraysk/xtorm/xproto"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Capabilities returns the capabilities last reported by the server.
+func (c *conn) Capabilities() netx.Capabilities {
+	return c.capabilities
+}
+
+// GetCapabilities requests the capabilities of the server, which are retained and returned by Capabilities().
+func (c *conn) GetCapabilities(ctx context.Context) (netx.Capabilities, error) {
+	var buf [5]byte
+
+	if _, err := c.WriteOne(ctx, xproto.CapabilitiesGet(buf[:0])); err != nil {
+		return c.capabilities, err
+	}
+	return c.capabilities, nil
+}
+
+// SetCapability sets the capability name to value, failing if the server refuses.
+func (c *conn) SetCapability(ctx context.Context, name string, value interface{}) error {
+	var buf [64]byte
+
+	b, err := xproto.CapabilitySet(buf[:0], name, value)
+	if err != nil {
+		return err
+	}
+	_, err = c.WriteOne(ctx, b)
+	return err
+}
+
+// decodeCapabilities decodes a Capabilities message into c.capabilities.
+func (c *conn) decodeCapabilities(b []byte) error {
+	var cs mysqlx_connection.Capabilities
+
+	if err := proto.Unmarshal(b, &cs); err != nil {
+		return fmt.Errorf("failed to unmarshal Capabilities: %w", err)
+	}
+	caps := netx.Capabilities{
+		ServerHello: c.capabilities.ServerHello,
+		Values:      make(map[string]interface{}, len(cs.GetCapabilities())),
+	}
+	for _, capability := range cs.GetCapabilities() {
+		v, err := xdecode.Any(capability.GetValue())
+		if err != nil {
+			return fmt.Errorf("failed to decode capability %s: %w", capability.GetName(), err)
+		}
+		caps.Values[capability.GetName()] = v
+		switch capability.GetName() {
+		case "tls":
+			caps.TLS, _ = v.(bool)
+		case "authentication.mechanisms":
+			caps.AuthenticationMechanisms = stringValues(v)
+		case "compression":
+			if m, ok := v.(map[string]interface{}); ok {
+				caps.CompressionAlgorithms = stringValues(m["algorithm"])
+			}
+		case "client.pwd_expire_ok":
+			caps.ClientPwdExpireOK, _ = v.(bool)
+		case "session_connect_attrs":
+			caps.SessionConnectAttrs = true
+		case "doc.formats":
+			caps.DocFormats, _ = v.(string)
+		}
+	}
+	c.capabilities = caps
+	return nil
+}
+
+// stringValues returns the strings of v, a single string or an array of strings.
+func stringValues(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		s := make([]string, 0, len(v))
+		for _, x := range v {
+			if x, ok := x.(string); ok {
+				s = append(s, x)
+			}
+		}
+		return s
+	}
+	return nil
+}
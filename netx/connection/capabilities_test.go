@@ -0,0 +1,91 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_connection"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_datatypes"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_notice"
+)
+
+func anyScalar(s *mysqlx_datatypes.Scalar) *mysqlx_datatypes.Any {
+	return &mysqlx_datatypes.Any{Type: mysqlx_datatypes.Any_SCALAR.Enum(), Scalar: s}
+}
+
+func anyString(s string) *mysqlx_datatypes.Any {
+	return anyScalar(&mysqlx_datatypes.Scalar{
+		Type:    mysqlx_datatypes.Scalar_V_STRING.Enum(),
+		VString: &mysqlx_datatypes.Scalar_String{Value: []byte(s)},
+	})
+}
+
+func anyBool(b bool) *mysqlx_datatypes.Any {
+	return anyScalar(&mysqlx_datatypes.Scalar{Type: mysqlx_datatypes.Scalar_V_BOOL.Enum(), VBool: proto.Bool(b)})
+}
+
+func anyArray(values ...*mysqlx_datatypes.Any) *mysqlx_datatypes.Any {
+	return &mysqlx_datatypes.Any{Type: mysqlx_datatypes.Any_ARRAY.Enum(), Array: &mysqlx_datatypes.Array{Value: values}}
+}
+
+func TestReadCapabilities(t *testing.T) {
+	var b []byte
+
+	b = appendFrame(t, b, mysqlx.ServerMessages_NOTICE, &mysqlx_notice.Frame{
+		Type:  proto.Uint32(uint32(mysqlx_notice.Frame_SERVER_HELLO)),
+		Scope: mysqlx_notice.Frame_GLOBAL.Enum(),
+	})
+	b = appendFrame(t, b, mysqlx.ServerMessages_CONN_CAPABILITIES, &mysqlx_connection.Capabilities{
+		Capabilities: []*mysqlx_connection.Capability{
+			{Name: proto.String("tls"), Value: anyBool(true)},
+			{Name: proto.String("authentication.mechanisms"), Value: anyArray(anyString("MYSQL41"), anyString("SHA256_MEMORY"))},
+			{Name: proto.String("doc.formats"), Value: anyString("text")},
+			{Name: proto.String("client.pwd_expire_ok"), Value: anyBool(false)},
+			{Name: proto.String("compression"), Value: &mysqlx_datatypes.Any{
+				Type: mysqlx_datatypes.Any_OBJECT.Enum(),
+				Obj: &mysqlx_datatypes.Object{Fld: []*mysqlx_datatypes.Object_ObjectField{
+					{Key: proto.String("algorithm"), Value: anyArray(anyString("deflate_stream"), anyString("zstd_stream"))},
+				}},
+			}},
+		},
+	})
+
+	c := newTestConn(b)
+	if _, err := c.Read(context.Background(), mysqlx.ClientMessages_CON_CAPABILITIES_GET); err != nil {
+		t.Fatalf("read failed: %s", err)
+	}
+	caps := c.Capabilities()
+	if !caps.ServerHello || !caps.TLS || caps.ClientPwdExpireOK || caps.DocFormats != "text" {
+		t.Fatalf("unexpected capabilities %+v", caps)
+	}
+	if !reflect.DeepEqual(caps.AuthenticationMechanisms, []string{"MYSQL41", "SHA256_MEMORY"}) ||
+		!caps.HasAuthenticationMechanism("SHA256_MEMORY") || caps.HasAuthenticationMechanism("PLAIN") {
+		t.Fatalf("unexpected authentication mechanisms %v", caps.AuthenticationMechanisms)
+	}
+	if !caps.HasCompressionAlgorithm("deflate_stream") || caps.HasCompressionAlgorithm("lz4_message") {
+		t.Fatalf("unexpected compression algorithms %v", caps.CompressionAlgorithms)
+	}
+	if len(caps.Values) != 5 {
+		t.Fatalf("expected 5 capability values, got %v", caps.Values)
+	}
+}
+
+func TestReadCapabilitySetError(t *testing.T) {
+	b := appendFrame(t, nil, mysqlx.ServerMessages_ERROR, &mysqlx.Error{
+		Severity: mysqlx.Error_ERROR.Enum(),
+		Code:     proto.Uint32(5001),
+		SqlState: proto.String("HY000"),
+		Msg:      proto.String("Capability prepare failed for 'tls'"),
+	})
+
+	_, err := newTestConn(b).Read(context.Background(), mysqlx.ClientMessages_CON_CAPABILITIES_SET)
+	var m *MySqlXError
+	if !errors.As(err, &m) || m.Code != 5001 {
+		t.Fatalf("expected CapabilitiesSet error, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package connection
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/renthraysk/xtorm/netx/connector/compression"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_connection"
+	"github.com/renthraysk/xtorm/xproto"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// minCompressSize is the size of the smallest unit of work worth compressing.
+const minCompressSize = 1024
+
+// EnableCompression sets the compression capability to algorithm, and if the server accepts, compresses units of work
+// sent with Send() from then on. Messages the server compresses are decompressed regardless.
+func (c *conn) EnableCompression(ctx context.Context, algorithm compression.Algorithm) error {
+	if err := c.SetCapability(ctx, "compression", xproto.Object{{Key: "algorithm", Value: algorithm.Name()}}); err != nil {
+		return err
+	}
+	c.compressor = algorithm.New()
+	return nil
+}
+
+// compress returns the unit of work b, wrapped in a Compression message if compression is enabled, and b is large
+// enough to be worth compressing.
+func (c *conn) compress(b []byte) ([]byte, error) {
+	if c.compressor == nil || len(b) < minCompressSize {
+		return b, nil
+	}
+	var err error
+
+	if c.zout, err = c.compressor.Compress(c.zout[:0], b); err != nil {
+		return nil, fmt.Errorf("failed to compress: %w", err)
+	}
+	c.zmsg = xproto.Compression(c.zmsg[:0], uint64(len(b)), c.zout)
+	return c.zmsg, nil
+}
+
+// decompress decompresses the Compression message b, with the messages within being read by zr.
+func (c *conn) decompress(b []byte) error {
+	var cm mysqlx_connection.Compression
+
+	if c.compressor == nil || c.r == c.zr {
+		return ErrUnexpectedCompression
+	}
+	if err := proto.Unmarshal(b, &cm); err != nil {
+		return fmt.Errorf("failed to unmarshal Compression: %w", err)
+	}
+	n := int(cm.GetUncompressedSize())
+	if n > cap(c.zin) {
+		c.zin = make([]byte, n)
+	}
+	c.zin = c.zin[:n]
+	if err := c.compressor.Decompress(c.zin, cm.GetPayload()); err != nil {
+		return fmt.Errorf("failed to decompress: %w", err)
+	}
+	if c.zr == nil {
+		c.zr = bufio.NewReaderSize(bytes.NewReader(c.zin), minBufSize)
+	} else {
+		c.zr.Reset(bytes.NewReader(c.zin))
+	}
+	return nil
+}
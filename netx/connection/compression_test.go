@@ -0,0 +1,78 @@
+package connection
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/netx/connector/compression/deflate"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_connection"
+)
+
+func TestReadCompressed(t *testing.T) {
+	server := deflate.New().New()
+
+	var m []byte
+	m = appendResultSet(t, m, "a", 3)
+	m = appendFrame(t, m, mysqlx.ServerMessages_RESULTSET_FETCH_DONE, nil)
+	m = appendFrame(t, m, mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK, nil)
+	payload, err := server.Compress(nil, m)
+	if err != nil {
+		t.Fatalf("failed to compress: %s", err)
+	}
+	b := appendFrame(t, nil, mysqlx.ServerMessages_COMPRESSION, &mysqlx_connection.Compression{
+		UncompressedSize: proto.Uint64(uint64(len(m))),
+		Payload:          payload,
+	})
+	b = appendFrame(t, b, mysqlx.ServerMessages_OK, nil)
+
+	c := newTestConn(b)
+	if _, err := c.Read(context.Background(), mysqlx.ClientMessages_SQL_STMT_EXECUTE); err != ErrUnexpectedCompression {
+		t.Fatalf("expected unexpected compression error, got %v", err)
+	}
+
+	c = newTestConn(b)
+	c.compressor = deflate.New().New()
+	r, err := c.Read(context.Background(), mysqlx.ClientMessages_SQL_STMT_EXECUTE)
+	if err != nil {
+		t.Fatalf("read failed: %s", err)
+	}
+	if len(r.ResultSets) != 1 || len(r.ResultSets[0].Rows) != 3 {
+		t.Fatalf("unexpected response %+v", r)
+	}
+	if _, err := c.Read(context.Background(), mysqlx.ClientMessages_SESS_RESET); err != nil {
+		t.Fatalf("read following compressed messages failed: %s", err)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	c := newTestConn(nil)
+	small := appendFrame(t, nil, mysqlx.ServerMessages_OK, nil)
+	if w, err := c.compress(small); err != nil || !bytes.Equal(w, small) {
+		t.Fatalf("expected uncompressed without compressor, got %v %v", w, err)
+	}
+
+	c.compressor = deflate.New().New()
+	if w, err := c.compress(small); err != nil || !bytes.Equal(w, small) {
+		t.Fatalf("expected small unit of work uncompressed, got %v %v", w, err)
+	}
+	large := bytes.Repeat(small, minCompressSize)
+	w, err := c.compress(large)
+	if err != nil {
+		t.Fatalf("failed to compress: %s", err)
+	}
+	if mysqlx.ClientMessages_Type(w[4]) != mysqlx.ClientMessages_COMPRESSION || len(w) >= len(large) {
+		t.Fatalf("expected smaller Compression message, got %d bytes", len(w))
+	}
+	var cm mysqlx_connection.Compression
+	if err := proto.Unmarshal(w[5:], &cm); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	out := make([]byte, cm.GetUncompressedSize())
+	if err := deflate.New().New().Decompress(out, cm.GetPayload()); err != nil || !bytes.Equal(out, large) {
+		t.Fatalf("failed to decompress: %v", err)
+	}
+}
@@ -9,12 +9,15 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"time"
 
 	"github.com/renthraysk/xtorm/netx"
 	"github.com/renthraysk/xtorm/netx/connector/authentication"
+	"github.com/renthraysk/xtorm/netx/connector/compression"
 	"github.com/renthraysk/xtorm/protobuf/mysqlx"
 	"github.com/renthraysk/xtorm/protobuf/mysqlx_resultset"
 	"github.com/renthraysk/xtorm/protobuf/mysqlx_session"
+	"github.com/renthraysk/xtorm/xproto"
 
 	"github.com/golang/protobuf/proto"
 )
@@ -23,7 +26,18 @@ const minBufSize = 4096
 
 type conn struct {
 	netConn net.Conn
-	r       *bufio.Reader
+	// nr buffers reading from netConn
+	nr *bufio.Reader
+	// r is the reader messages are read from, either nr, or zr while reading the messages of a Compression message
+	r            *bufio.Reader
+	zr           *bufio.Reader
+	capabilities netx.Capabilities
+	// broken is set once an error has left the connection unusable, so closing need not wait on the server
+	broken bool
+	// compressor compresses Send()s, and decompresses Compression messages, once compression is enabled
+	compressor compression.Compressor
+	// zin buffers decompressed messages, zout compressed payloads, and zmsg Compression messages
+	zin, zout, zmsg []byte
 }
 
 func (c *conn) IsSecure() bool {
@@ -36,30 +50,76 @@ func (c *conn) IsSecure() bool {
 
 func (c *conn) Reset(netConn net.Conn) {
 	c.netConn = netConn
-	c.r.Reset(netConn)
+	c.nr.Reset(netConn)
+	c.r = c.nr
 }
 
 func New(netConn net.Conn) *conn {
+	nr := bufio.NewReaderSize(netConn, minBufSize)
 	return &conn{
 		netConn: netConn,
-		r:       bufio.NewReaderSize(netConn, minBufSize),
+		nr:      nr,
+		r:       nr,
 	}
 }
 
+// closeTimeout bounds waiting for the server to acknowledge closing the connection.
+const closeTimeout = time.Second
+
+// Close closes the connection as CloseContext, waiting at most closeTimeout for the server to acknowledge.
 func (c *conn) Close() error {
+	return c.CloseContext(context.Background())
+}
+
+// CloseContext sends Connection Close, and waits for the server to acknowledge until ctx is done, or at most
+// closeTimeout, before closing the socket. Closing gracefully avoids the server logging the connection as aborted.
+func (c *conn) CloseContext(ctx context.Context) error {
+	if c.broken {
+		return c.netConn.Close()
+	}
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > closeTimeout {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, closeTimeout)
+		defer cancel()
+	}
+	var buf [5]byte
+
+	_, err := c.WriteOne(ctx, xproto.ConnectionClose(buf[:0]))
+	if cerr := c.netConn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Abort closes the socket without waiting on the server, as on failing to establish the connection.
+func (c *conn) Abort() error {
+	c.broken = true
 	return c.netConn.Close()
 }
 
+// check marks the connection as broken if err leaves it unusable, any error other than a non fatal server error.
+func (c *conn) check(err error) error {
+	var m *MySqlXError
+	var ac *ErrRequireAuthenticateContinue
+
+	if err != nil && !(errors.As(err, &m) && !m.IsFatal()) && !errors.As(err, &ac) {
+		c.broken = true
+	}
+	return err
+}
+
 func (c *conn) WriteOne(ctx context.Context, b []byte) (netx.Response, error) {
 
 	deadline, _ := ctx.Deadline()
 	if err := c.netConn.SetDeadline(deadline); err != nil {
-		return nil, fmt.Errorf("SetDeadline failed: %w", err)
+		return netx.Response{}, fmt.Errorf("SetDeadline failed: %w", err)
 	}
 	if _, err := c.netConn.Write(b); err != nil {
-		return nil, fmt.Errorf("Write failed: %w", err)
+		return netx.Response{}, c.check(fmt.Errorf("Write failed: %w", err))
 	}
-	return c.Read(ctx, mysqlx.ClientMessages_Type(b[4]))
+	r, err := c.Read(ctx, mysqlx.ClientMessages_Type(b[4]))
+	return r, c.check(err)
 }
 
 func (c *conn) Send(ctx context.Context, b []byte) ([]netx.Response, error) {
@@ -67,10 +127,15 @@ func (c *conn) Send(ctx context.Context, b []byte) ([]netx.Response, error) {
 	if err := c.netConn.SetDeadline(deadline); err != nil {
 		return nil, fmt.Errorf("SetDeadline failed: %w", err)
 	}
-	if _, err := c.netConn.Write(b); err != nil {
-		return nil, fmt.Errorf("Write failed: %w", err)
+	w, err := c.compress(b)
+	if err != nil {
+		return nil, err
 	}
-	return c.ReadResponsesToSlice(ctx, make([]netx.Response, 0, 16), b)
+	if _, err := c.netConn.Write(w); err != nil {
+		return nil, c.check(fmt.Errorf("Write failed: %w", err))
+	}
+	r, err := c.ReadResponsesToSlice(ctx, make([]netx.Response, 0, 16), b)
+	return r, c.check(err)
 }
 
 /*
@@ -107,36 +172,44 @@ func (c *conn) ReadResponsesToSlice(ctx context.Context, r []netx.Response, b []
 
 func (c *conn) Read(ctx context.Context, ct mysqlx.ClientMessages_Type) (netx.Response, error) {
 	var buf []byte
-	var cmd *mysqlx_resultset.ColumnMetaData
+	var rs *netx.ResultSet
+	var outParams bool
 
+	r := netx.Response{Type: ct}
 	for {
 		b, err := c.r.Peek(5)
 		if err != nil {
-			return nil, err
+			if err == io.EOF && c.r == c.zr && c.r.Buffered() == 0 {
+				// Read all the messages of a Compression message, continue with those following it
+				c.r = c.nr
+				continue
+			}
+			return r, err
 		}
 		u := binary.LittleEndian.Uint32(b)
 		st := mysqlx.ServerMessages_Type(b[4])
 		c.r.Discard(5) // Peeked 5 bytes so Discard cannot fail.
 		n := int(u)
 		if n < 1 {
-			return nil, fmt.Errorf("length too short, got %d", n)
+			return r, fmt.Errorf("length too short, got %d", n)
 		}
 		n--
 
 		//		log.Printf("<< %s %s(%d)", ct.String(), st.String(), n)
 
+		b = b[:0]
 		if n > 0 {
 			b, err = c.r.Peek(n)
 			if err != nil {
 				if err != bufio.ErrBufferFull {
-					return nil, err
+					return r, err
 				}
 				if n > cap(buf) {
 					buf = make([]byte, n)
 				}
 				b = buf[:n]
 				if _, err := io.ReadFull(c.r, b); err != nil {
-					return nil, err
+					return r, err
 				}
 				n = 0 // Read n bytes, no need to Discard()
 			}
@@ -147,22 +220,57 @@ func (c *conn) Read(ctx context.Context, ct mysqlx.ClientMessages_Type) (netx.Re
 			mysqlx.ServerMessages_SESS_AUTHENTICATE_OK,
 			mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK:
 			c.r.Discard(n)
-			return nil, nil
+			if rs != nil {
+				r.ResultSets = append(r.ResultSets, rs)
+			}
+			return r, nil
 
 		case mysqlx.ServerMessages_RESULTSET_COLUMN_META_DATA:
-			if cmd == nil {
-				cmd = new(mysqlx_resultset.ColumnMetaData)
+			if rs == nil {
+				rs = &netx.ResultSet{OutParams: outParams}
 			}
+			cmd := new(mysqlx_resultset.ColumnMetaData)
 			if err := proto.Unmarshal(b, cmd); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal ColumnMetaData: %w", err)
+				return r, fmt.Errorf("failed to unmarshal ColumnMetaData: %w", err)
+			}
+			rs.Columns = append(rs.Columns, cmd)
+
+		case mysqlx.ServerMessages_RESULTSET_ROW:
+			if rs == nil {
+				// Rows fetched from a cursor follow on from the ColumnMetaData sent in response to opening it.
+				if ct != mysqlx.ClientMessages_CURSOR_FETCH {
+					return r, ErrUnexpectedRow
+				}
+				rs = new(netx.ResultSet)
+			}
+			if err := rs.AppendRow(b); err != nil {
+				return r, err
+			}
+
+		case mysqlx.ServerMessages_RESULTSET_FETCH_DONE:
+
+		case mysqlx.ServerMessages_RESULTSET_FETCH_DONE_MORE_RESULTSETS,
+			mysqlx.ServerMessages_RESULTSET_FETCH_DONE_MORE_OUT_PARAMS:
+			// Stored procedure CALLs may return several result sets, and a final result set of OUT parameters.
+			if rs != nil {
+				r.ResultSets = append(r.ResultSets, rs)
+				rs = nil
 			}
+			outParams = st == mysqlx.ServerMessages_RESULTSET_FETCH_DONE_MORE_OUT_PARAMS
+
+		case mysqlx.ServerMessages_RESULTSET_FETCH_SUSPENDED:
+			// Cursor has further rows to fetch, StmtExecuteOk still follows.
+			if rs == nil {
+				rs = new(netx.ResultSet)
+			}
+			rs.Suspended = true
 
 		case mysqlx.ServerMessages_ERROR:
 			var er mysqlx.Error
 
 			defer c.r.Discard(n)
 			if err := proto.Unmarshal(b, &er); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal Error: %w", err)
+				return r, fmt.Errorf("failed to unmarshal Error: %w", err)
 			}
 			e := &MySqlXError{
 				Severity: er.GetSeverity(),
@@ -170,29 +278,50 @@ func (c *conn) Read(ctx context.Context, ct mysqlx.ClientMessages_Type) (netx.Re
 				SqlState: er.GetSqlState(),
 				Msg:      er.GetMsg(),
 			}
+			r.Err = e
 			if e.IsFatal() {
-				return e, e
+				return r, e
 			}
 			switch ct {
-			case mysqlx.ClientMessages_SESS_RESET,
+			case mysqlx.ClientMessages_CON_CAPABILITIES_GET,
+				mysqlx.ClientMessages_CON_CAPABILITIES_SET,
+				mysqlx.ClientMessages_SESS_RESET,
 				mysqlx.ClientMessages_SESS_AUTHENTICATE_START,
 				mysqlx.ClientMessages_SESS_AUTHENTICATE_CONTINUE:
-				return e, e
+				return r, e
 			}
-			return e, nil
+			return r, nil
 
 		case mysqlx.ServerMessages_NOTICE:
+			if err := c.decodeNotice(&r, b); err != nil {
+				return r, err
+			}
+
+		case mysqlx.ServerMessages_CONN_CAPABILITIES:
+			defer c.r.Discard(n)
+			if ct != mysqlx.ClientMessages_CON_CAPABILITIES_GET {
+				return r, ErrUnexpectedCapabilities
+			}
+			return r, c.decodeCapabilities(b)
+
+		case mysqlx.ServerMessages_COMPRESSION:
+			if err := c.decompress(b); err != nil {
+				return r, err
+			}
+			c.r.Discard(n)
+			c.r = c.zr
+			continue
 
 		case mysqlx.ServerMessages_SESS_AUTHENTICATE_CONTINUE:
 			defer c.r.Discard(n)
 			if ct != mysqlx.ClientMessages_SESS_AUTHENTICATE_START {
-				return nil, ErrUnexpectedAuthenticateContinue
+				return r, ErrUnexpectedAuthenticateContinue
 			}
 			var ac mysqlx_session.AuthenticateContinue
 			if err := proto.Unmarshal(b, &ac); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal AuthenticateContinue: %w", err)
+				return r, fmt.Errorf("failed to unmarshal AuthenticateContinue: %w", err)
 			}
-			return nil, &ErrRequireAuthenticateContinue{AuthData: ac.AuthData}
+			return r, &ErrRequireAuthenticateContinue{AuthData: ac.AuthData}
 
 		default:
 
@@ -0,0 +1,251 @@
+package connection
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/netx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_resultset"
+)
+
+func appendFrame(t *testing.T, p []byte, st mysqlx.ServerMessages_Type, m proto.Message) []byte {
+	t.Helper()
+	var b []byte
+	if m != nil {
+		var err error
+		if b, err = proto.Marshal(m); err != nil {
+			t.Fatalf("failed to marshal %s: %s", st, err)
+		}
+	}
+	p = append(p, 0, 0, 0, 0, byte(st))
+	binary.LittleEndian.PutUint32(p[len(p)-5:], uint32(1+len(b)))
+	return append(p, b...)
+}
+
+func appendResultSet(t *testing.T, p []byte, name string, rows int) []byte {
+	p = appendFrame(t, p, mysqlx.ServerMessages_RESULTSET_COLUMN_META_DATA, &mysqlx_resultset.ColumnMetaData{
+		Type: mysqlx_resultset.ColumnMetaData_UINT.Enum(),
+		Name: []byte(name),
+	})
+	for i := 0; i < rows; i++ {
+		p = appendFrame(t, p, mysqlx.ServerMessages_RESULTSET_ROW, &mysqlx_resultset.Row{Field: [][]byte{{byte(i)}}})
+	}
+	return p
+}
+
+func newTestConn(b []byte) *conn {
+	nr := bufio.NewReader(bytes.NewReader(b))
+	return &conn{nr: nr, r: nr}
+}
+
+func TestReadResultSet(t *testing.T) {
+	var b []byte
+
+	// Rows larger than the read buffer are read into a reused buffer, so must be copied
+	large := [][]byte{bytes.Repeat([]byte{'a'}, 5000), bytes.Repeat([]byte{'b'}, 5000)}
+	for _, name := range []string{"id", "name"} {
+		b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_COLUMN_META_DATA, &mysqlx_resultset.ColumnMetaData{
+			Type: mysqlx_resultset.ColumnMetaData_BYTES.Enum(),
+			Name: []byte(name),
+		})
+	}
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_ROW, &mysqlx_resultset.Row{Field: [][]byte{{1}, {}}})
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_ROW, &mysqlx_resultset.Row{Field: [][]byte{{2}, large[0]}})
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_ROW, &mysqlx_resultset.Row{Field: [][]byte{{3}, large[1]}})
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_FETCH_DONE, nil)
+	b = appendFrame(t, b, mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK, nil)
+
+	r, err := newTestConn(b).Read(context.Background(), mysqlx.ClientMessages_SQL_STMT_EXECUTE)
+	if err != nil {
+		t.Fatalf("read failed: %s", err)
+	}
+	if len(r.ResultSets) != 1 {
+		t.Fatalf("expected 1 result set, got %d", len(r.ResultSets))
+	}
+	rs := r.ResultSets[0]
+	if len(rs.Columns) != 2 || string(rs.Columns[0].GetName()) != "id" || string(rs.Columns[1].GetName()) != "name" {
+		t.Fatalf("unexpected columns %v", rs.Columns)
+	}
+	expected := []netx.Row{{{1}, {}}, {{2}, large[0]}, {{3}, large[1]}}
+	if len(rs.Rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(rs.Rows))
+	}
+	for i, row := range rs.Rows {
+		if len(row) != len(expected[i]) {
+			t.Fatalf("row %d: expected %d fields, got %d", i, len(expected[i]), len(row))
+		}
+		for j, f := range row {
+			if !bytes.Equal(f, expected[i][j]) {
+				t.Fatalf("row %d field %d: unexpected value %.10q", i, j, f)
+			}
+		}
+	}
+}
+
+func TestReadNoResultSet(t *testing.T) {
+	b := appendFrame(t, nil, mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK, nil)
+
+	r, err := newTestConn(b).Read(context.Background(), mysqlx.ClientMessages_SQL_STMT_EXECUTE)
+	if err != nil {
+		t.Fatalf("read failed: %s", err)
+	}
+	if r.ResultSets != nil {
+		t.Fatalf("expected no result sets, got %v", r.ResultSets)
+	}
+}
+
+func TestReadUnexpectedRow(t *testing.T) {
+	b := appendFrame(t, nil, mysqlx.ServerMessages_RESULTSET_ROW, &mysqlx_resultset.Row{Field: [][]byte{{1}}})
+
+	if _, err := newTestConn(b).Read(context.Background(), mysqlx.ClientMessages_SQL_STMT_EXECUTE); err != ErrUnexpectedRow {
+		t.Fatalf("expected ErrUnexpectedRow, got %v", err)
+	}
+}
+
+func TestReadMalformedRow(t *testing.T) {
+	b := appendResultSet(t, nil, "a", 0)
+	// Field length exceeds the message
+	b = append(b, 4, 0, 0, 0, byte(mysqlx.ServerMessages_RESULTSET_ROW), 0x0A, 0x05, 0x01)
+
+	if _, err := newTestConn(b).Read(context.Background(), mysqlx.ClientMessages_SQL_STMT_EXECUTE); err == nil {
+		t.Fatal("expected malformed row error")
+	}
+}
+
+func TestReadMultipleResultSets(t *testing.T) {
+	var b []byte
+
+	b = appendResultSet(t, b, "a", 2)
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_FETCH_DONE_MORE_RESULTSETS, nil)
+	b = appendResultSet(t, b, "b", 1)
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_FETCH_DONE_MORE_OUT_PARAMS, nil)
+	b = appendResultSet(t, b, "out", 1)
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_FETCH_DONE, nil)
+	b = appendFrame(t, b, mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK, nil)
+
+	r, err := newTestConn(b).Read(context.Background(), mysqlx.ClientMessages_SQL_STMT_EXECUTE)
+	if err != nil {
+		t.Fatalf("read failed: %s", err)
+	}
+	if len(r.ResultSets) != 3 {
+		t.Fatalf("expected 3 result sets, got %d", len(r.ResultSets))
+	}
+	for i, expected := range []struct {
+		name      string
+		rows      int
+		outParams bool
+	}{{"a", 2, false}, {"b", 1, false}, {"out", 1, true}} {
+		rs := r.ResultSets[i]
+		if string(rs.Columns[0].GetName()) != expected.name || len(rs.Rows) != expected.rows || rs.OutParams != expected.outParams {
+			t.Fatalf("unexpected result set %d: %q %d rows, out params %v", i, rs.Columns[0].GetName(), len(rs.Rows), rs.OutParams)
+		}
+	}
+}
+
+func TestReadCursor(t *testing.T) {
+	var b []byte
+
+	// Open and each Fetch end with StmtExecuteOk, whether or not the rows were suspended
+	b = appendResultSet(t, b, "a", 2)
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_FETCH_SUSPENDED, nil)
+	b = appendFrame(t, b, mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK, nil)
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_ROW, &mysqlx_resultset.Row{Field: [][]byte{{2}}})
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_FETCH_SUSPENDED, nil)
+	b = appendFrame(t, b, mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK, nil)
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_ROW, &mysqlx_resultset.Row{Field: [][]byte{{3}}})
+	b = appendFrame(t, b, mysqlx.ServerMessages_RESULTSET_FETCH_DONE, nil)
+	b = appendFrame(t, b, mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK, nil)
+	b = appendFrame(t, b, mysqlx.ServerMessages_OK, nil)
+
+	c := newTestConn(b)
+	r, err := c.Read(context.Background(), mysqlx.ClientMessages_CURSOR_OPEN)
+	if err != nil {
+		t.Fatalf("read failed: %s", err)
+	}
+	if len(r.ResultSets) != 1 || !r.ResultSets[0].Suspended || len(r.ResultSets[0].Rows) != 2 {
+		t.Fatalf("unexpected cursor open response %+v", r)
+	}
+	for _, suspended := range []bool{true, false} {
+		r, err = c.Read(context.Background(), mysqlx.ClientMessages_CURSOR_FETCH)
+		if err != nil {
+			t.Fatalf("read failed: %s", err)
+		}
+		if len(r.ResultSets) != 1 || r.ResultSets[0].Suspended != suspended || len(r.ResultSets[0].Rows) != 1 {
+			t.Fatalf("unexpected cursor fetch response %+v", r)
+		}
+	}
+	r, err = c.Read(context.Background(), mysqlx.ClientMessages_CURSOR_CLOSE)
+	if err != nil {
+		t.Fatalf("read failed: %s", err)
+	}
+	if len(r.ResultSets) != 0 {
+		t.Fatalf("unexpected cursor close response %+v", r)
+	}
+}
+
+func TestCloseContext(t *testing.T) {
+	client, server := net.Pipe()
+	ok := appendFrame(t, nil, mysqlx.ServerMessages_OK, nil)
+	done := make(chan error, 1)
+	go func() {
+		var b [5]byte
+		if _, err := io.ReadFull(server, b[:]); err != nil {
+			done <- err
+			return
+		}
+		if mysqlx.ClientMessages_Type(b[4]) != mysqlx.ClientMessages_CON_CLOSE {
+			done <- fmt.Errorf("expected Close, got %d", b[4])
+			return
+		}
+		if _, err := server.Write(ok); err != nil {
+			done <- err
+			return
+		}
+		// Client closes the socket after reading Ok
+		_, err := server.Read(b[:])
+		if err != io.EOF {
+			done <- fmt.Errorf("expected EOF, got %v", err)
+			return
+		}
+		done <- nil
+	}()
+
+	if err := New(client).CloseContext(context.Background()); err != nil {
+		t.Fatalf("close failed: %s", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("server failed: %s", err)
+	}
+}
+
+func TestCloseBroken(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	c := New(client)
+	if c.check(ErrUnexpectedRow); !c.broken {
+		t.Fatal("expected connection to be broken")
+	}
+	// Broken connections close without waiting on the server, which never responds here
+	if err := c.Close(); err != nil {
+		t.Fatalf("close failed: %s", err)
+	}
+	// Aborting closes without waiting on the server either
+	c = New(client)
+	if err := c.Abort(); err != nil || !c.broken {
+		t.Fatalf("abort failed: %v", err)
+	}
+	c = New(client)
+	if c.check(&MySqlXError{Severity: mysqlx.Error_ERROR}); c.broken {
+		t.Fatal("expected non fatal error not to break the connection")
+	}
+}
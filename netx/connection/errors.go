@@ -2,7 +2,9 @@ package connection
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/renthraysk/xtorm/netx/connection/errs"
 	"github.com/renthraysk/xtorm/protobuf/mysqlx"
 )
 
@@ -21,6 +23,86 @@ func (e *MySqlXError) IsFatal() bool {
 	return e.Severity == mysqlx.Error_FATAL
 }
 
+// IsDuplicateKey reports whether the error is a violation of a primary or unique key.
+func (e *MySqlXError) IsDuplicateKey() bool {
+	switch e.Code {
+	case errs.ErDupKey, errs.ErDupEntry, errs.ErDupUnique, errs.ErDupEntryWithKeyName:
+		return true
+	}
+	return false
+}
+
+// IsForeignKeyViolation reports whether the error is a violation of a foreign key, either a referenced row does not
+// exist, or a row is still referenced.
+func (e *MySqlXError) IsForeignKeyViolation() bool {
+	switch e.Code {
+	case errs.ErNoReferencedRow, errs.ErRowIsReferenced, errs.ErNoReferencedRow2, errs.ErRowIsReferenced2:
+		return true
+	}
+	return false
+}
+
+// IsConstraint reports whether the error is a violation of a constraint, key, foreign key, NOT NULL or CHECK.
+func (e *MySqlXError) IsConstraint() bool {
+	switch e.Code {
+	case errs.ErBadNullError, errs.ErCheckConstraintViolated:
+		return true
+	}
+	return e.IsDuplicateKey() || e.IsForeignKeyViolation()
+}
+
+// IsDeadlock reports whether the transaction was rolled back after deadlocking.
+func (e *MySqlXError) IsDeadlock() bool {
+	return e.Code == errs.ErLockDeadlock
+}
+
+// IsLockWaitTimeout reports whether a statement timed out waiting for a row lock.
+func (e *MySqlXError) IsLockWaitTimeout() bool {
+	return e.Code == errs.ErLockWaitTimeout
+}
+
+// IsRetryable reports whether the error is transient, such that the unit of work may succeed if resent.
+func (e *MySqlXError) IsRetryable() bool {
+	return !e.IsFatal() && (e.IsDeadlock() || e.IsLockWaitTimeout())
+}
+
+// DuplicateKey returns the name of the key violated, parsed from the message of a duplicate key error.
+// MySQL 8.0.19+ qualifies the key name with the table name, which is removed.
+func (e *MySqlXError) DuplicateKey() (string, bool) {
+	const prefix = "for key '"
+
+	if !e.IsDuplicateKey() {
+		return "", false
+	}
+	i := strings.LastIndex(e.Msg, prefix)
+	if i < 0 || !strings.HasSuffix(e.Msg, "'") {
+		return "", false
+	}
+	key := e.Msg[i+len(prefix) : len(e.Msg)-1]
+	if j := strings.LastIndexByte(key, '.'); j >= 0 {
+		key = key[j+1:]
+	}
+	return key, true
+}
+
+// Is supports errors.Is() matching the error against the sentinel errors ErrDuplicateKey, ErrForeignKeyViolation,
+// ErrConstraint, ErrDeadlock and ErrLockWaitTimeout.
+func (e *MySqlXError) Is(target error) bool {
+	switch target {
+	case ErrDuplicateKey:
+		return e.IsDuplicateKey()
+	case ErrForeignKeyViolation:
+		return e.IsForeignKeyViolation()
+	case ErrConstraint:
+		return e.IsConstraint()
+	case ErrDeadlock:
+		return e.IsDeadlock()
+	case ErrLockWaitTimeout:
+		return e.IsLockWaitTimeout()
+	}
+	return false
+}
+
 type errorString string
 
 func (e errorString) Error() string {
@@ -29,6 +111,18 @@ func (e errorString) Error() string {
 
 const (
 	ErrUnexpectedAuthenticateContinue = errorString("unexpected AuthenticateContinue")
+	ErrUnexpectedRow                  = errorString("unexpected Row without ColumnMetaData")
+	ErrUnexpectedCapabilities         = errorString("unexpected Capabilities")
+	ErrUnexpectedCompression          = errorString("unexpected Compression")
+)
+
+// Sentinel errors for errors.Is() matching classes of MySqlXError
+const (
+	ErrDuplicateKey        = errorString("duplicate key")
+	ErrForeignKeyViolation = errorString("foreign key violation")
+	ErrConstraint          = errorString("constraint violation")
+	ErrDeadlock            = errorString("deadlock")
+	ErrLockWaitTimeout     = errorString("lock wait timeout")
 )
 
 type ErrRequireAuthenticateContinue struct {
@@ -0,0 +1,79 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/renthraysk/xtorm/netx/connection/errs"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+)
+
+func TestMySqlXErrorIs(t *testing.T) {
+	tests := []struct {
+		code    uint32
+		targets []error
+	}{
+		{errs.ErDupEntry, []error{ErrDuplicateKey, ErrConstraint}},
+		{errs.ErNoReferencedRow2, []error{ErrForeignKeyViolation, ErrConstraint}},
+		{errs.ErRowIsReferenced2, []error{ErrForeignKeyViolation, ErrConstraint}},
+		{errs.ErCheckConstraintViolated, []error{ErrConstraint}},
+		{errs.ErLockDeadlock, []error{ErrDeadlock}},
+		{errs.ErLockWaitTimeout, []error{ErrLockWaitTimeout}},
+		{errs.ErParseError, nil},
+	}
+	sentinels := []error{ErrDuplicateKey, ErrForeignKeyViolation, ErrConstraint, ErrDeadlock, ErrLockWaitTimeout}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			// Wrapped, as would be returned from XPipe.Send
+			err := fmt.Errorf("failed: %w", &MySqlXError{Severity: mysqlx.Error_ERROR, Code: tt.code})
+			for _, s := range sentinels {
+				expected := false
+				for _, target := range tt.targets {
+					expected = expected || target == s
+				}
+				if errors.Is(err, s) != expected {
+					t.Fatalf("errors.Is(%d, %q) expected %v", tt.code, s, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestMySqlXErrorIsRetryable(t *testing.T) {
+	if !(&MySqlXError{Severity: mysqlx.Error_ERROR, Code: errs.ErLockDeadlock}).IsRetryable() {
+		t.Fatal("expected deadlock to be retryable")
+	}
+	if (&MySqlXError{Severity: mysqlx.Error_FATAL, Code: errs.ErLockDeadlock}).IsRetryable() {
+		t.Fatal("expected fatal error not to be retryable")
+	}
+	if (&MySqlXError{Severity: mysqlx.Error_ERROR, Code: errs.ErDupEntry}).IsRetryable() {
+		t.Fatal("expected duplicate key not to be retryable")
+	}
+}
+
+func TestMySqlXErrorDuplicateKey(t *testing.T) {
+	tests := []struct {
+		code     uint32
+		msg      string
+		key      string
+		expected bool
+	}{
+		{errs.ErDupEntry, "Duplicate entry '1' for key 'PRIMARY'", "PRIMARY", true},
+		{errs.ErDupEntry, "Duplicate entry 'a' for key 'foo.val_UNIQUE'", "val_UNIQUE", true},
+		{errs.ErDupEntry, "Duplicate entry 'for key 'x'' for key 'foo.val'", "val", true},
+		{errs.ErDupEntry, "Duplicate entry", "", false},
+		{errs.ErLockDeadlock, "for key 'PRIMARY'", "", false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			key, ok := (&MySqlXError{Code: tt.code, Msg: tt.msg}).DuplicateKey()
+			if ok != tt.expected || key != tt.key {
+				t.Fatalf("expected %q %v, got %q %v", tt.key, tt.expected, key, ok)
+			}
+		})
+	}
+}
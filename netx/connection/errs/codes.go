@@ -1077,6 +1077,7 @@ const (
 	ErUnsupportedAlterEncryptionInplace                                   = 3187
 	ErKeyringUdfKeyringServiceError                                       = 3188
 	ErUserColumnOldLength                                                 = 3189
+	ErCheckConstraintViolated                                             = 3819
 
 	ErXServiceError     = 5010
 	ErXSession          = 5011
@@ -0,0 +1,66 @@
+package connection
+
+import (
+	"fmt"
+
+	"github.com/renthraysk/xtorm/netx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_notice"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// decodeNotice decodes a Notice Frame, attaching warnings & session state changes to the response r.
+// Global notices are not specific to the message being responded to, so other than recording the ServerHello
+// greeting in the connection's capabilities, are ignored.
+func (c *conn) decodeNotice(r *netx.Response, b []byte) error {
+	var f mysqlx_notice.Frame
+
+	if err := proto.Unmarshal(b, &f); err != nil {
+		return fmt.Errorf("failed to unmarshal Notice Frame: %w", err)
+	}
+	if f.GetScope() != mysqlx_notice.Frame_LOCAL {
+		if mysqlx_notice.Frame_Type(f.GetType()) == mysqlx_notice.Frame_SERVER_HELLO {
+			c.capabilities.ServerHello = true
+		}
+		return nil
+	}
+	switch mysqlx_notice.Frame_Type(f.GetType()) {
+	case mysqlx_notice.Frame_WARNING:
+		var w mysqlx_notice.Warning
+
+		if err := proto.Unmarshal(f.GetPayload(), &w); err != nil {
+			return fmt.Errorf("failed to unmarshal Warning: %w", err)
+		}
+		r.Warnings = append(r.Warnings, netx.Warning{
+			Level: w.GetLevel(),
+			Code:  w.GetCode(),
+			Msg:   w.GetMsg(),
+		})
+
+	case mysqlx_notice.Frame_SESSION_STATE_CHANGED:
+		var ssc mysqlx_notice.SessionStateChanged
+
+		if err := proto.Unmarshal(f.GetPayload(), &ssc); err != nil {
+			return fmt.Errorf("failed to unmarshal SessionStateChanged: %w", err)
+		}
+		switch ssc.GetParam() {
+		case mysqlx_notice.SessionStateChanged_ROWS_AFFECTED:
+			if v := ssc.GetValue(); len(v) > 0 {
+				r.RowsAffected = v[0].GetVUnsignedInt()
+			}
+		case mysqlx_notice.SessionStateChanged_GENERATED_INSERT_ID:
+			if v := ssc.GetValue(); len(v) > 0 {
+				r.LastInsertID = v[0].GetVUnsignedInt()
+			}
+		case mysqlx_notice.SessionStateChanged_GENERATED_DOCUMENT_IDS:
+			for _, v := range ssc.GetValue() {
+				r.GeneratedDocumentIDs = append(r.GeneratedDocumentIDs, string(v.GetVOctets().GetValue()))
+			}
+		case mysqlx_notice.SessionStateChanged_PRODUCED_MESSAGE:
+			if v := ssc.GetValue(); len(v) > 0 {
+				r.ProducedMessage = string(v[0].GetVString().GetValue())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package connection
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/netx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_datatypes"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_notice"
+)
+
+func frame(t *testing.T, scope mysqlx_notice.Frame_Scope, typ mysqlx_notice.Frame_Type, payload proto.Message) []byte {
+	t.Helper()
+	p, err := proto.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %s", err)
+	}
+	b, err := proto.Marshal(&mysqlx_notice.Frame{Type: proto.Uint32(uint32(typ)), Scope: scope.Enum(), Payload: p})
+	if err != nil {
+		t.Fatalf("failed to marshal frame: %s", err)
+	}
+	return b
+}
+
+func stateChanged(param mysqlx_notice.SessionStateChanged_Parameter, values ...*mysqlx_datatypes.Scalar) *mysqlx_notice.SessionStateChanged {
+	return &mysqlx_notice.SessionStateChanged{Param: param.Enum(), Value: values}
+}
+
+func uintScalar(x uint64) *mysqlx_datatypes.Scalar {
+	return &mysqlx_datatypes.Scalar{Type: mysqlx_datatypes.Scalar_V_UINT.Enum(), VUnsignedInt: proto.Uint64(x)}
+}
+
+func octetsScalar(s string) *mysqlx_datatypes.Scalar {
+	return &mysqlx_datatypes.Scalar{Type: mysqlx_datatypes.Scalar_V_OCTETS.Enum(), VOctets: &mysqlx_datatypes.Scalar_Octets{Value: []byte(s)}}
+}
+
+func TestDecodeNotice(t *testing.T) {
+	tests := []struct {
+		scope    mysqlx_notice.Frame_Scope
+		typ      mysqlx_notice.Frame_Type
+		payload  proto.Message
+		expected netx.Response
+	}{
+		{mysqlx_notice.Frame_LOCAL, mysqlx_notice.Frame_SESSION_STATE_CHANGED,
+			stateChanged(mysqlx_notice.SessionStateChanged_ROWS_AFFECTED, uintScalar(42)),
+			netx.Response{RowsAffected: 42}},
+		{mysqlx_notice.Frame_LOCAL, mysqlx_notice.Frame_SESSION_STATE_CHANGED,
+			stateChanged(mysqlx_notice.SessionStateChanged_GENERATED_INSERT_ID, uintScalar(7)),
+			netx.Response{LastInsertID: 7}},
+		{mysqlx_notice.Frame_LOCAL, mysqlx_notice.Frame_SESSION_STATE_CHANGED,
+			stateChanged(mysqlx_notice.SessionStateChanged_GENERATED_DOCUMENT_IDS, octetsScalar("a"), octetsScalar("b")),
+			netx.Response{GeneratedDocumentIDs: []string{"a", "b"}}},
+		{mysqlx_notice.Frame_LOCAL, mysqlx_notice.Frame_SESSION_STATE_CHANGED,
+			stateChanged(mysqlx_notice.SessionStateChanged_PRODUCED_MESSAGE, &mysqlx_datatypes.Scalar{
+				Type:    mysqlx_datatypes.Scalar_V_STRING.Enum(),
+				VString: &mysqlx_datatypes.Scalar_String{Value: []byte("Records: 1")},
+			}),
+			netx.Response{ProducedMessage: "Records: 1"}},
+		{mysqlx_notice.Frame_LOCAL, mysqlx_notice.Frame_WARNING,
+			&mysqlx_notice.Warning{Level: mysqlx_notice.Warning_NOTE.Enum(), Code: proto.Uint32(1051), Msg: proto.String("Unknown table")},
+			netx.Response{Warnings: []netx.Warning{{Level: mysqlx_notice.Warning_NOTE, Code: 1051, Msg: "Unknown table"}}}},
+		{mysqlx_notice.Frame_GLOBAL, mysqlx_notice.Frame_WARNING,
+			&mysqlx_notice.Warning{Code: proto.Uint32(1051), Msg: proto.String("Unknown table")},
+			netx.Response{}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var r netx.Response
+
+			if err := new(conn).decodeNotice(&r, frame(t, tt.scope, tt.typ, tt.payload)); err != nil {
+				t.Fatalf("failed to decode notice: %s", err)
+			}
+			if !reflect.DeepEqual(r, tt.expected) {
+				t.Fatalf("expected %+v, got %+v", tt.expected, r)
+			}
+		})
+	}
+}
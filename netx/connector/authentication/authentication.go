@@ -14,3 +14,9 @@ type StartContinuer interface {
 	Starter
 	Continue(buf []byte, credentials Credentials, authData []byte) []byte
 }
+
+// Mechanism is implemented by Starters that report the name of the authentication mechanism they start, so it can
+// be checked against those the server supports.
+type Mechanism interface {
+	Mechanism() string
+}
@@ -8,10 +8,14 @@ import (
 
 type auth struct{}
 
+const mechanism = "PLAIN"
+
 func New() *auth {
 	return &auth{}
 }
 
+func (auth) Mechanism() string { return mechanism }
+
 func (auth) Start(buf []byte, c authentication.Credentials) []byte {
 	n := len(c.Database()) + 1 + len(c.UserName()) + 1 + len(c.Password())
 
@@ -25,5 +29,5 @@ func (auth) Start(buf []byte, c authentication.Credentials) []byte {
 	i++
 	copy(ad[i:], c.Password())
 
-	return xproto.AuthenticateStart(buf, "PLAIN", ad)
+	return xproto.AuthenticateStart(buf, mechanism, ad)
 }
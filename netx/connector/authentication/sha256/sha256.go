@@ -11,12 +11,16 @@ import (
 
 type auth struct{}
 
+const mechanism = "SHA256_MEMORY"
+
 func New() *auth {
 	return &auth{}
 }
 
+func (auth) Mechanism() string { return mechanism }
+
 func (auth) Start(buf []byte, c authentication.Credentials) []byte {
-	return xproto.AuthenticateStart(buf, "SHA256_MEMORY", nil)
+	return xproto.AuthenticateStart(buf, mechanism, nil)
 }
 
 func (auth) Continue(buf []byte, c authentication.Credentials, authData []byte) []byte {
@@ -0,0 +1,18 @@
+package compression
+
+// Algorithm is an algorithm the messages of a connection can be compressed with.
+type Algorithm interface {
+	// Name of the algorithm, as reported in the server's compression capability, eg deflate_stream
+	Name() string
+	// New returns a Compressor for a single connection
+	New() Compressor
+}
+
+// Compressor compresses and decompresses the payloads of the Compression messages of a single connection. Messages are
+// compressed and decompressed in the order sent and received, so stream algorithms may retain state between them.
+type Compressor interface {
+	// Compress appends the compressed form of src to dst
+	Compress(dst, src []byte) ([]byte, error)
+	// Decompress decompresses src into dst, which is sized to the uncompressed size
+	Decompress(dst, src []byte) error
+}
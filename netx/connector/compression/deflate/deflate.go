@@ -0,0 +1,82 @@
+package deflate
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+
+	"github.com/renthraysk/xtorm/netx/connector/compression"
+)
+
+const name = "deflate_stream"
+
+type algorithm struct {
+	level int
+}
+
+// New returns the deflate_stream algorithm, compressing with the default compression level.
+func New() *algorithm {
+	return &algorithm{level: zlib.DefaultCompression}
+}
+
+// NewLevel returns the deflate_stream algorithm, compressing with level, as with compress/zlib.
+func NewLevel(level int) *algorithm {
+	return &algorithm{level: level}
+}
+
+func (algorithm) Name() string { return name }
+
+func (a *algorithm) New() compression.Compressor {
+	return &compressor{level: a.level}
+}
+
+// appender is an io.Writer appending to a slice.
+type appender struct {
+	p []byte
+}
+
+func (a *appender) Write(p []byte) (int, error) {
+	a.p = append(a.p, p...)
+	return len(p), nil
+}
+
+// compressor compresses a single zlib stream across messages, flushing at the end of each.
+type compressor struct {
+	level int
+	w     *zlib.Writer
+	a     appender
+	r     io.ReadCloser
+	rb    bytes.Buffer
+}
+
+func (c *compressor) Compress(dst, src []byte) ([]byte, error) {
+	if c.w == nil {
+		w, err := zlib.NewWriterLevel(&c.a, c.level)
+		if err != nil {
+			return dst, err
+		}
+		c.w = w
+	}
+	c.a.p = dst
+	if _, err := c.w.Write(src); err != nil {
+		return dst, err
+	}
+	if err := c.w.Flush(); err != nil {
+		return dst, err
+	}
+	dst, c.a.p = c.a.p, nil
+	return dst, nil
+}
+
+func (c *compressor) Decompress(dst, src []byte) error {
+	c.rb.Write(src)
+	if c.r == nil {
+		r, err := zlib.NewReader(&c.rb)
+		if err != nil {
+			return err
+		}
+		c.r = r
+	}
+	_, err := io.ReadFull(c.r, dst)
+	return err
+}
@@ -0,0 +1,28 @@
+package deflate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	w := New().New()
+	r := New().New()
+
+	var p []byte
+	for i, msg := range []string{strings.Repeat("insert ", 100), "", strings.Repeat("select ", 1000), "x"} {
+		var err error
+
+		if p, err = w.Compress(p[:0], []byte(msg)); err != nil {
+			t.Fatalf("failed to compress message %d: %s", i, err)
+		}
+		out := make([]byte, len(msg))
+		if err := r.Decompress(out, p); err != nil {
+			t.Fatalf("failed to decompress message %d: %s", i, err)
+		}
+		if !bytes.Equal(out, []byte(msg)) {
+			t.Fatalf("message %d mismatch, got %q", i, out)
+		}
+	}
+}
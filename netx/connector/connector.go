@@ -6,6 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/renthraysk/xtorm/netx"
 	"github.com/renthraysk/xtorm/netx/connection"
@@ -13,9 +18,13 @@ import (
 	"github.com/renthraysk/xtorm/netx/connector/authentication"
 	"github.com/renthraysk/xtorm/netx/connector/authentication/mysql41"
 	"github.com/renthraysk/xtorm/netx/connector/authentication/plain"
+	"github.com/renthraysk/xtorm/netx/connector/compression"
 	"github.com/renthraysk/xtorm/xproto"
 )
 
+// ErrTLSNotSupported is returned when configured to connect with TLS, but the server does not support it.
+var ErrTLSNotSupported = errors.New("server does not support TLS")
+
 type Dialer interface {
 	DialContext(ctx context.Context, network, address string) (net.Conn, error)
 }
@@ -27,6 +36,8 @@ type Connector struct {
 	dialer         Dialer
 	tlsConfig      *tls.Config
 	authentication authentication.Starter
+	compression    []compression.Algorithm
+	connectAttrs   map[string]string
 	userName       string
 	password       string
 	database       string
@@ -38,6 +49,7 @@ func New(network, address string, options ...Option) (*Connector, error) {
 		address:        address,
 		dialer:         new(net.Dialer),
 		authentication: mysql41.New(),
+		connectAttrs:   defaultConnectAttributes(),
 	}
 	for _, opt := range options {
 		if err := opt(cnn); err != nil {
@@ -64,14 +76,20 @@ func (c *Connector) New(ctx context.Context) (netx.Conn, error) {
 
 	conn := connection.New(netConn)
 
-	if _, ok := netConn.(*net.TCPConn); ok && c.tlsConfig != nil {
-		b, err := xproto.CapabilitySet("tls", true)
-		if err != nil {
-			return nil, err
+	caps, err := conn.GetCapabilities(ctx)
+	if err != nil {
+		conn.Abort()
+		return nil, fmt.Errorf("failed to get capabilities: %w", err)
+	}
+
+	if c.tlsConfig != nil && !conn.IsSecure() {
+		if !caps.TLS {
+			conn.Abort()
+			return nil, ErrTLSNotSupported
 		}
-		// @TODO response test
-		if _, err := conn.Send(ctx, b); err != nil {
-			return nil, err
+		if err := conn.SetCapability(ctx, "tls", true); err != nil {
+			conn.Abort()
+			return nil, fmt.Errorf("failed to enable TLS: %w", err)
 		}
 		tlsConn := tls.Client(netConn, c.tlsConfig)
 		if err := tlsConn.Handshake(); err != nil {
@@ -79,23 +97,69 @@ func (c *Connector) New(ctx context.Context) (netx.Conn, error) {
 			return nil, fmt.Errorf("failed TLS handshake: %w", err)
 		}
 		conn.Reset(tlsConn)
+		// Authentication mechanisms available differ once secure
+		if caps, err = conn.GetCapabilities(ctx); err != nil {
+			conn.Abort()
+			return nil, fmt.Errorf("failed to get capabilities: %w", err)
+		}
 	}
 
-	if err := conn.Authenticate(ctx, c, c.authentication); err != nil {
+	if err := conn.SetCapability(ctx, "session_connect_attrs", xproto.AnyObject(c.connectAttrs)); err != nil {
+		// Servers prior to 8.0.16 refuse connection attributes, which are informational, so only fatal errors fail
 		var m *connection.MySqlXError
-		if errors.As(err, &m) && m.Code == errs.ErAccessDeniedError && conn.IsSecure() {
+		if !errors.As(err, &m) || m.IsFatal() {
+			conn.Abort()
+			return nil, fmt.Errorf("failed to set connection attributes: %w", err)
+		}
+	}
+
+	for _, a := range c.compression {
+		if caps.HasCompressionAlgorithm(a.Name()) {
+			if err := conn.EnableCompression(ctx, a); err != nil {
+				conn.Abort()
+				return nil, fmt.Errorf("failed to enable compression: %w", err)
+			}
+			break
+		}
+	}
+
+	if err := conn.Authenticate(ctx, c, c.starter(caps, conn.IsSecure())); err != nil {
+		var m *connection.MySqlXError
+		if errors.As(err, &m) && m.Code == errs.ErAccessDeniedError && conn.IsSecure() && supports(caps, plain.New()) {
 			// Connected securely, so can attempt to authenticate with PLAIN,
 			// which will populate the cache for caching_sha2 and sha256_password to start working
 			if err2 := conn.Authenticate(ctx, c, plain.New()); err2 == nil {
 				return conn, nil
 			}
 		}
-		conn.Close()
+		conn.Abort()
 		return nil, err
 	}
 	return conn, nil
 }
 
+// starter returns the authentication mechanism to authenticate with, the configured mechanism if the server supports
+// it, otherwise PLAIN if secure, or MYSQL41.
+func (c *Connector) starter(caps netx.Capabilities, secure bool) authentication.Starter {
+	if supports(caps, c.authentication) {
+		return c.authentication
+	}
+	if p := plain.New(); secure && supports(caps, p) {
+		return p
+	}
+	if m := mysql41.New(); supports(caps, m) {
+		return m
+	}
+	return c.authentication
+}
+
+// supports reports whether the server reported supporting the authentication mechanism of starter. Starters that do
+// not report their mechanism, and servers that do not report mechanisms, are assumed to be supported.
+func supports(caps netx.Capabilities, starter authentication.Starter) bool {
+	m, ok := starter.(authentication.Mechanism)
+	return !ok || len(caps.AuthenticationMechanisms) == 0 || caps.HasAuthenticationMechanism(m.Mechanism())
+}
+
 // Option is a functional option for creating the Connector
 type Option func(*Connector) error
 
@@ -126,6 +190,44 @@ func WithUserPassword(userName, password string) Option {
 	}
 }
 
+// WithCompression sets the compression algorithms, in order of preference, to compress messages with. The first the
+// server supports is used, if the server supports none, messages are sent uncompressed.
+func WithCompression(algorithms ...compression.Algorithm) Option {
+	return func(cnn *Connector) error {
+		cnn.compression = algorithms
+		return nil
+	}
+}
+
+// WithConnectAttributes adds attributes to those sent when connecting, which are shown in
+// performance_schema.session_connect_attrs. Names beginning with an underscore are reserved for the defaults,
+// _client_name, _pid, _os and _platform, the default program_name may be overridden.
+func WithConnectAttributes(attrs map[string]string) Option {
+	return func(cnn *Connector) error {
+		for name, value := range attrs {
+			if strings.HasPrefix(name, "_") {
+				return fmt.Errorf("reserved connection attribute name %q", name)
+			}
+			cnn.connectAttrs[name] = value
+		}
+		return nil
+	}
+}
+
+// defaultConnectAttributes returns the attributes sent when connecting, identifying the client and process.
+func defaultConnectAttributes() map[string]string {
+	attrs := map[string]string{
+		"_client_name": "xtorm",
+		"_pid":         strconv.Itoa(os.Getpid()),
+		"_os":          runtime.GOOS,
+		"_platform":    runtime.GOARCH,
+	}
+	if len(os.Args) > 0 {
+		attrs["program_name"] = filepath.Base(os.Args[0])
+	}
+	return attrs
+}
+
 // WithTLSConfig set the TLS configuration to connect to mysqlx with.
 func WithTLSConfig(tlsConfig *tls.Config) Option {
 	return func(cnn *Connector) error {
@@ -0,0 +1,28 @@
+package connector
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestConnectAttributes(t *testing.T) {
+	c, err := New("tcp", "localhost:33060", WithConnectAttributes(map[string]string{"program_name": "test", "app": "shop"}))
+	if err != nil {
+		t.Fatalf("failed to create connector: %s", err)
+	}
+	for name, expected := range map[string]string{
+		"_client_name": "xtorm",
+		"_pid":         strconv.Itoa(os.Getpid()),
+		"program_name": "test",
+		"app":          "shop",
+	} {
+		if v := c.connectAttrs[name]; v != expected {
+			t.Fatalf("expected connection attribute %s of %q, got %q", name, expected, v)
+		}
+	}
+
+	if _, err := New("tcp", "localhost:33060", WithConnectAttributes(map[string]string{"_client_name": "other"})); err == nil {
+		t.Fatal("expected error overriding reserved connection attribute")
+	}
+}
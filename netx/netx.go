@@ -2,9 +2,137 @@ package netx
 
 import (
 	"context"
+	"encoding/binary"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_notice"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_sql"
 )
 
-type Response interface {
+// Response is the outcome of a single client message.
+type Response struct {
+	// Type of the client message this is in response to
+	Type mysqlx.ClientMessages_Type
+	// Err is the error the server responded with, if any
+	Err error
+
+	RowsAffected         uint64
+	LastInsertID         uint64
+	GeneratedDocumentIDs []string
+	Warnings             []Warning
+	// ProducedMessage is the informational message of statements such as ALTER TABLE, "Records: 0  Duplicates: 0  Warnings: 0"
+	ProducedMessage string
+
+	// ResultSets returned, for messages that read rows
+	ResultSets []*ResultSet
+}
+
+// Warning is a warning or note raised by the server while processing a message.
+type Warning struct {
+	Level mysqlx_notice.Warning_Level
+	Code  uint32
+	Msg   string
+}
+
+// Capabilities are the capabilities the server reported for a connection, via CapabilitiesGet.
+type Capabilities struct {
+	// TLS reports whether the connection can be upgraded to TLS, false once upgraded
+	TLS bool
+	// AuthenticationMechanisms usable on the connection, eg MYSQL41, PLAIN, SHA256_MEMORY
+	AuthenticationMechanisms []string
+	// CompressionAlgorithms supported, eg deflate_stream, lz4_message, zstd_stream
+	CompressionAlgorithms []string
+	// ClientPwdExpireOK reports whether accounts with expired passwords may connect, to change the password
+	ClientPwdExpireOK bool
+	// SessionConnectAttrs reports whether connection attributes were reported as supported
+	SessionConnectAttrs bool
+	// DocFormats is the format documents are returned in, eg "text"
+	DocFormats string
+	// ServerHello reports whether the server greeted the connection with a ServerHello notice
+	ServerHello bool
+	// Values are all the capabilities reported, as decoded by xdecode.Any
+	Values map[string]interface{}
+}
+
+// HasAuthenticationMechanism reports whether the authentication mechanism name is usable on the connection.
+func (c Capabilities) HasAuthenticationMechanism(name string) bool {
+	return contains(c.AuthenticationMechanisms, name)
+}
+
+// HasCompressionAlgorithm reports whether the compression algorithm name is supported.
+func (c Capabilities) HasCompressionAlgorithm(name string) bool {
+	return contains(c.CompressionAlgorithms, name)
+}
+
+func contains(s []string, x string) bool {
+	for _, v := range s {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+// TxStatement classifies the transaction control statements of a unit of work.
+type TxStatement int
+
+const (
+	// NotTxStatement is any message that is not a transaction control statement
+	NotTxStatement TxStatement = iota
+	// SetTransaction is SET TRANSACTION, setting the characteristics of the next transaction
+	SetTransaction
+	// StartTransaction is START TRANSACTION or BEGIN
+	StartTransaction
+	// Commit is a plain COMMIT, as appended by Builder.Tx
+	Commit
+	// EndTransaction is any other COMMIT or ROLLBACK, such as COMMIT AND CHAIN
+	EndTransaction
+)
+
+// TxStatementOf classifies the message m, including its length and type prefix, as a transaction control statement.
+func TxStatementOf(m []byte) (TxStatement, error) {
+	if len(m) < 5 || mysqlx.ClientMessages_Type(m[4]) != mysqlx.ClientMessages_SQL_STMT_EXECUTE {
+		return NotTxStatement, nil
+	}
+	var s mysqlx_sql.StmtExecute
+	if err := proto.Unmarshal(m[5:], &s); err != nil {
+		return NotTxStatement, err
+	}
+	stmt := strings.ToUpper(string(s.GetStmt()))
+	switch {
+	case strings.HasPrefix(stmt, "SET TRANSACTION"):
+		return SetTransaction, nil
+	case strings.HasPrefix(stmt, "START TRANSACTION"), strings.HasPrefix(stmt, "BEGIN"):
+		return StartTransaction, nil
+	case stmt == "COMMIT":
+		return Commit, nil
+	case strings.HasPrefix(stmt, "COMMIT"), strings.HasPrefix(stmt, "ROLLBACK"):
+		return EndTransaction, nil
+	}
+	return NotTxStatement, nil
+}
+
+// InTransaction reports whether message i of the unit of work b is within a transaction explicitly started by an
+// earlier message, and not since committed or rolled back, so failing at i may leave the transaction open.
+func InTransaction(b []byte, i int) bool {
+	open := false
+	for j, n := 0, 0; j+5 <= len(b) && n < i; n++ {
+		end := j + 4 + int(binary.LittleEndian.Uint32(b[j:]))
+		if end > len(b) {
+			break
+		}
+		switch s, _ := TxStatementOf(b[j:end]); s {
+		case StartTransaction:
+			open = true
+		case Commit, EndTransaction:
+			open = false
+		}
+		j = end
+	}
+	return open
 }
 
 type Connector interface {
@@ -17,6 +145,11 @@ type Sender interface {
 
 type Conn interface {
 	Sender
+	// Close closes the connection, gracefully if possible, waiting a bounded time for the server to acknowledge
 	Close() error
+	// CloseContext closes the connection, gracefully if possible, waiting for the server to acknowledge until ctx is done
+	CloseContext(ctx context.Context) error
 	IsSecure() bool
+	// Capabilities returns the capabilities the server reported when connecting
+	Capabilities() Capabilities
 }
@@ -0,0 +1,23 @@
+package netx
+
+import (
+	"testing"
+
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+func TestInTransaction(t *testing.T) {
+	var b []byte
+	for _, stmt := range []string{"INSERT INTO log VALUES (1)", "START TRANSACTION", "UPDATE foo SET val = 1", "COMMIT",
+		"INSERT INTO log VALUES (2)"} {
+		var err error
+		if b, err = xproto.StmtExecute(b, stmt, nil); err != nil {
+			t.Fatalf("failed to append %s: %s", stmt, err)
+		}
+	}
+	for i, expected := range []bool{false, false, true, true, false} {
+		if InTransaction(b, i) != expected {
+			t.Fatalf("expected message %d in transaction %v", i, expected)
+		}
+	}
+}
@@ -35,7 +35,7 @@ func (p *poolCh) Close(ctx context.Context) error {
 			if !ok {
 				return nil
 			}
-			conn.Close()
+			conn.CloseContext(ctx)
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
@@ -78,6 +78,16 @@ func (p *poolCh) Send(ctx context.Context, wt []byte) ([]netx.Response, error) {
 		conn.Close()
 		return r, err
 	}
+	for i, rr := range r {
+		if rr.Err != nil {
+			// Error may have left a transaction open, that a subsequent unit of work would implicitly commit.
+			if netx.InTransaction(wt, i) {
+				conn.Close()
+				return r, nil
+			}
+			break
+		}
+	}
 
 	ch = p.getCh()
 	select {
@@ -88,4 +98,7 @@ func (p *poolCh) Send(ctx context.Context, wt []byte) ([]netx.Response, error) {
 	return r, nil
 }
 
+// DiscardsOnError reports the pool discards connections on which a unit of work failed leaving a transaction open.
+func (p *poolCh) DiscardsOnError() bool { return true }
+
 var _ pool.Pool = (*poolCh)(nil)
@@ -0,0 +1,55 @@
+package netx
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_resultset"
+)
+
+// ResultSet is the column metadata and rows returned by a statement that produces a result set, such as a SELECT.
+type ResultSet struct {
+	Columns []*mysqlx_resultset.ColumnMetaData
+	Rows    []Row
+	// Suspended is true if the rows are a batch read from a cursor, with further rows remaining to be fetched.
+	Suspended bool
+	// OutParams is true if the result set is the single row of OUT parameters of a stored procedure CALL.
+	OutParams bool
+}
+
+// Row is the encoded fields of a single row in a result set, one per column.
+// Each field is encoded as described by the ColumnMetaData of the column, with an empty field being NULL.
+type Row [][]byte
+
+var errMalformedRow = errors.New("malformed Row")
+
+// AppendRow parses a mysqlx_resultset.Row protobuf and appends it to the result set.
+// The fields are copied, so b may be reused after returning.
+func (rs *ResultSet) AppendRow(b []byte) error {
+	const (
+		tagRowField = 1
+		wireBytes   = 2
+	)
+	n := 0
+	for i := 0; i < len(b); {
+		if b[i] != tagRowField<<3|wireBytes {
+			return errMalformedRow
+		}
+		x, j := binary.Uvarint(b[i+1:])
+		if j <= 0 || x > uint64(len(b)-i-1-j) {
+			return errMalformedRow
+		}
+		i += 1 + j + int(x)
+		n++
+	}
+	row := make(Row, 0, n)
+	data := append(make([]byte, 0, len(b)), b...)
+	for i := 0; i < len(data); {
+		x, j := binary.Uvarint(data[i+1:])
+		i += 1 + j
+		row = append(row, data[i:i+int(x):i+int(x)])
+		i += int(x)
+	}
+	rs.Rows = append(rs.Rows, row)
+	return nil
+}
@@ -0,0 +1,326 @@
+// Package retry provides a netx.Sender that resends units of work that failed due to transient conditions, such as
+// deadlocks. As a unit of work is a self contained buffer, with no client side transaction state, it can be resent
+// verbatim, but only safely when the failed attempt left no effects behind, see Sender.
+package retry
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/netx"
+	"github.com/renthraysk/xtorm/netx/connection"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_expect"
+)
+
+const (
+	defaultAttempts = 3
+	defaultBase     = 10 * time.Millisecond
+	defaultMax      = time.Second
+)
+
+// Backoff returns the duration to wait before the next attempt, after attempt number of attempts have failed.
+type Backoff func(attempt int) time.Duration
+
+// Exponential returns a Backoff that waits a random duration up to base doubled for every attempt, capped at max.
+func Exponential(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := max
+		// Test against max shifted right, as base shifted left may overflow
+		if n := uint(attempt - 1); n < 63 && base <= max>>n {
+			d = base << n
+		}
+		return time.Duration(rand.Int63n(int64(d) + 1))
+	}
+}
+
+// Retryable reports whether the result r, err of sending the unit of work b can be resent.
+type Retryable func(b []byte, r []netx.Response, err error) bool
+
+// Discarder is implemented by Senders that discard the connection after a unit of work fails leaving a transaction
+// open, such as fifoch pools, so the transaction cannot be continued by a later unit of work.
+type Discarder interface {
+	DiscardsOnError() bool
+}
+
+// Sender resends units of work while the result is retryable.
+//
+// Resending is only safe if the failed attempt had no lasting effect. By default, deadlocks and lock wait timeouts are
+// only retried when the unit of work is a single transaction, a Builder.Tx, as otherwise statements autocommitted
+// before the failure would be applied again. The statements of the transaction must also be within
+// Builder.ExpectFailOnError, so those following the failure are skipped. The server rolls back the transaction on a
+// deadlock, but then executes any statements not skipped in autocommit mode. A lock wait timeout does not roll back
+// the transaction, so the COMMIT must be skipped too, as when the Builder.Tx is itself within ExpectFailOnError. This
+// leaves the transaction open, to be implicitly committed by the START TRANSACTION of the resent unit of work if sent
+// on the same connection. So lock wait timeouts are only retried when the wrapped Sender is a Discarder.
+type Sender struct {
+	sender    netx.Sender
+	attempts  int
+	backoff   Backoff
+	retryable Retryable
+}
+
+// New returns a Sender that resends units of work via s, while the result is retryable.
+func New(s netx.Sender, options ...Option) (*Sender, error) {
+	rs := &Sender{
+		sender:    s,
+		attempts:  defaultAttempts,
+		backoff:   Exponential(defaultBase, defaultMax),
+		retryable: IsRetryable,
+	}
+	for _, opt := range options {
+		if err := opt(rs); err != nil {
+			return nil, err
+		}
+	}
+	return rs, nil
+}
+
+// Send sends the unit of work b, resending it after a backoff while the result is retryable and attempts remain.
+// The result of the last attempt is returned.
+func (s *Sender) Send(ctx context.Context, b []byte) ([]netx.Response, error) {
+	for attempt := 1; ; attempt++ {
+		r, err := s.sender.Send(ctx, b)
+		if attempt >= s.attempts || !s.retryable(b, r, err) || (isLockWaitTimeout(r, err) && !s.discards()) {
+			return r, err
+		}
+		t := time.NewTimer(s.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return r, err
+		case <-t.C:
+		}
+	}
+}
+
+// discards reports whether the wrapped Sender discards connections after failures.
+func (s *Sender) discards() bool {
+	d, ok := s.sender.(Discarder)
+	return ok && d.DiscardsOnError()
+}
+
+// IsRetryable reports whether the unit of work b, a single transaction, failed within the transaction due to a
+// deadlock or lock wait timeout that left no effects, or whether the connection was lost before any response was read.
+func IsRetryable(b []byte, r []netx.Response, err error) bool {
+	for i, rr := range r {
+		if rr.Err != nil {
+			return isRetryableAt(b, i, rr.Err)
+		}
+	}
+	if err == nil {
+		return false
+	}
+	if isRetryableError(err) {
+		return isRetryableAt(b, len(r)-1, err)
+	}
+	if isDialError(err) {
+		return true
+	}
+	// The response being read when err occurred is the last, so no complete responses means the connection was lost
+	// before the server responded to anything, as when the server closes an idle connection. The server rolls back a
+	// transaction left open by a lost connection, but not statements autocommitted outside of one.
+	if len(r) <= 1 && isConnectionLost(err) {
+		_, ok := parseTx(b)
+		return ok
+	}
+	return false
+}
+
+// isRetryableAt reports whether the unit of work b failing at message i with err had no lasting effect.
+// On a deadlock the server rolls back the transaction, but then executes the messages following in autocommit mode,
+// so they must be skipped by the failed expectation block, up to the COMMIT. A lock wait timeout only rolls back the
+// failing statement, so the COMMIT must be skipped too, leaving the transaction open.
+func isRetryableAt(b []byte, i int, err error) bool {
+	if !isRetryableError(err) {
+		return false
+	}
+	t, ok := parseTx(b)
+	if !ok || i <= t.start || i >= t.commit {
+		return false
+	}
+	end := t.commit - 1
+	if errors.Is(err, connection.ErrLockWaitTimeout) {
+		end = t.commit
+	}
+	// Failing on error of the expectation blocks open at i, the innermost of which fails at i
+	var blocks []bool
+	for _, m := range t.msgs[:i] {
+		switch mysqlx.ClientMessages_Type(m[4]) {
+		case mysqlx.ClientMessages_EXPECT_OPEN:
+			noError, err := expectNoError(m, len(blocks) > 0 && blocks[len(blocks)-1])
+			if err != nil {
+				return false
+			}
+			blocks = append(blocks, noError)
+		case mysqlx.ClientMessages_EXPECT_CLOSE:
+			if len(blocks) == 0 {
+				return false
+			}
+			blocks = blocks[:len(blocks)-1]
+		}
+	}
+	failed := len(blocks)
+	for failed > 0 && !blocks[failed-1] {
+		failed--
+	}
+	if failed == 0 {
+		return false
+	}
+	depth, closed := len(blocks), false
+	for _, m := range t.msgs[i+1 : end+1] {
+		switch mysqlx.ClientMessages_Type(m[4]) {
+		case mysqlx.ClientMessages_EXPECT_OPEN:
+			depth++
+		case mysqlx.ClientMessages_EXPECT_CLOSE:
+			depth--
+			closed = closed || depth < failed
+		default:
+			if closed {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// expectNoError reports whether the ExpectOpen message m opens a block failing on error, given whether the enclosing
+// block does.
+func expectNoError(m []byte, prev bool) (bool, error) {
+	var o mysqlx_expect.Open
+
+	if err := proto.Unmarshal(m[5:], &o); err != nil {
+		return false, err
+	}
+	noError := prev && o.GetOp() == mysqlx_expect.Open_EXPECT_CTX_COPY_PREV
+	for _, c := range o.GetCond() {
+		if c.GetConditionKey() != uint32(mysqlx_expect.Open_Condition_EXPECT_NO_ERROR) {
+			continue
+		}
+		noError = c.GetOp() == mysqlx_expect.Open_Condition_EXPECT_OP_SET && string(c.GetConditionValue()) != "0"
+	}
+	return noError, nil
+}
+
+func isRetryableError(err error) bool {
+	var e *connection.MySqlXError
+	return errors.As(err, &e) && e.IsRetryable()
+}
+
+// isLockWaitTimeout reports whether the first error of the result is a lock wait timeout.
+func isLockWaitTimeout(r []netx.Response, err error) bool {
+	for _, rr := range r {
+		if rr.Err != nil {
+			return errors.Is(rr.Err, connection.ErrLockWaitTimeout)
+		}
+	}
+	return err != nil && errors.Is(err, connection.ErrLockWaitTimeout)
+}
+
+// tx is a unit of work consisting of a single transaction, as appended by Builder.Tx.
+type tx struct {
+	msgs [][]byte
+	// Message indexes of the START TRANSACTION and COMMIT
+	start, commit int
+}
+
+// parseTx parses the unit of work b, reporting whether it consists of a single transaction. Expectation blocks, and
+// SET TRANSACTION preceding the transaction, are permitted.
+func parseTx(b []byte) (tx, bool) {
+	t := tx{start: -1, commit: -1}
+	for j := 0; j < len(b); {
+		if j+5 > len(b) {
+			return t, false
+		}
+		end := j + 4 + int(binary.LittleEndian.Uint32(b[j:]))
+		if end > len(b) || end < j+5 {
+			return t, false
+		}
+		m := b[j:end]
+		j = end
+		k := len(t.msgs)
+		t.msgs = append(t.msgs, m)
+		switch mysqlx.ClientMessages_Type(m[4]) {
+		case mysqlx.ClientMessages_EXPECT_OPEN, mysqlx.ClientMessages_EXPECT_CLOSE:
+			continue
+		}
+		s, err := netx.TxStatementOf(m)
+		if err != nil {
+			return t, false
+		}
+		switch s {
+		case netx.SetTransaction:
+			if t.start >= 0 {
+				return t, false
+			}
+			continue
+		case netx.StartTransaction:
+			if t.start >= 0 {
+				return t, false
+			}
+			t.start = k
+			continue
+		case netx.Commit:
+			if t.start < 0 || t.commit >= 0 {
+				return t, false
+			}
+			t.commit = k
+			continue
+		case netx.EndTransaction:
+			return t, false
+		}
+		// Any other message must be within the transaction
+		if t.start < 0 || t.commit >= 0 {
+			return t, false
+		}
+	}
+	return t, t.commit >= 0
+}
+
+func isDialError(err error) bool {
+	var e *net.OpError
+	return errors.As(err, &e) && e.Op == "dial"
+}
+
+func isConnectionLost(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// Option is a functional option for creating the Sender
+type Option func(*Sender) error
+
+// WithAttempts sets the maximum number of attempts, including the first.
+func WithAttempts(attempts int) Option {
+	return func(s *Sender) error {
+		if attempts < 1 {
+			return errors.New("attempts must be at least 1")
+		}
+		s.attempts = attempts
+		return nil
+	}
+}
+
+// WithBackoff sets the backoff between attempts.
+func WithBackoff(backoff Backoff) Option {
+	return func(s *Sender) error {
+		s.backoff = backoff
+		return nil
+	}
+}
+
+// WithRetryable sets the function deciding whether a result is retryable, replacing IsRetryable.
+func WithRetryable(retryable Retryable) Option {
+	return func(s *Sender) error {
+		s.retryable = retryable
+		return nil
+	}
+}
@@ -0,0 +1,234 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/renthraysk/xtorm/netx"
+	"github.com/renthraysk/xtorm/netx/connection"
+	"github.com/renthraysk/xtorm/netx/connection/errs"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+type result struct {
+	r   []netx.Response
+	err error
+}
+
+// senderFunc returns results in order, repeating the last.
+type senderFunc struct {
+	results []result
+	sent    int
+}
+
+func (s *senderFunc) Send(ctx context.Context, b []byte) ([]netx.Response, error) {
+	i := s.sent
+	if i >= len(s.results) {
+		i = len(s.results) - 1
+	}
+	s.sent++
+	return s.results[i].r, s.results[i].err
+}
+
+func mysqlxError(severity mysqlx.Error_Severity, code uint32) error {
+	return &connection.MySqlXError{Severity: severity, Code: code}
+}
+
+// appendStmts appends a StmtExecute of each of stmts.
+func appendStmts(t *testing.T, p []byte, stmts ...string) []byte {
+	t.Helper()
+	for _, stmt := range stmts {
+		var err error
+		if p, err = xproto.StmtExecute(p, stmt, nil); err != nil {
+			t.Fatalf("failed to append %s: %s", stmt, err)
+		}
+	}
+	return p
+}
+
+// appendExpect appends the messages appended by f within an expectation block, failing on error if noError.
+func appendExpect(p []byte, op xproto.OpenCtxOperation, noError bool, f func(p []byte) []byte) []byte {
+	return xproto.ExpectClose(f(xproto.ExpectOpen(p, op, xproto.OpenConditionExpectNoError(noError))))
+}
+
+// failed returns the result of message i failing with err, and the expectation failure of the message following.
+func failed(i int, err error) result {
+	r := make([]netx.Response, i+2)
+	r[i].Err = err
+	r[i+1].Err = errors.New("expectation failed")
+	return result{r: r}
+}
+
+var ok = result{r: []netx.Response{{}, {}, {}}}
+
+func TestIsRetryable(t *testing.T) {
+	tx := appendStmts(t, nil, "START TRANSACTION", "UPDATE foo SET val = 1", "COMMIT")
+	autocommit := appendStmts(t, nil, "INSERT INTO log VALUES (1)", "UPDATE foo SET val = 1", "COMMIT")
+	twoTx := appendStmts(t, tx, "START TRANSACTION", "UPDATE foo SET val = 2", "COMMIT")
+
+	// START TRANSACTION, ExpectOpen, UPDATE, INSERT, ExpectClose, COMMIT
+	txExpect := appendStmts(t, nil, "START TRANSACTION")
+	txExpect = appendExpect(txExpect, xproto.OpenExpectCtxCopyPrev, true, func(p []byte) []byte {
+		return appendStmts(t, p, "UPDATE foo SET val = 1", "INSERT INTO log VALUES (1)")
+	})
+	txExpect = appendStmts(t, txExpect, "COMMIT")
+
+	// ExpectOpen, START TRANSACTION, UPDATE, COMMIT, ExpectClose
+	expectTx := appendExpect(nil, xproto.OpenExpectCtxCopyPrev, true, func(p []byte) []byte {
+		return appendStmts(t, p, "START TRANSACTION", "UPDATE foo SET val = 1", "COMMIT")
+	})
+
+	// START TRANSACTION, ExpectOpen, UPDATE, ExpectClose, INSERT, COMMIT
+	txExpectAfter := appendStmts(t, nil, "START TRANSACTION")
+	txExpectAfter = appendExpect(txExpectAfter, xproto.OpenExpectCtxCopyPrev, true, func(p []byte) []byte {
+		return appendStmts(t, p, "UPDATE foo SET val = 1")
+	})
+	txExpectAfter = appendStmts(t, txExpectAfter, "INSERT INTO log VALUES (1)", "COMMIT")
+
+	// START TRANSACTION, ExpectOpen, ExpectOpen, UPDATE, ExpectClose, INSERT, ExpectClose, COMMIT
+	txNested := appendStmts(t, nil, "START TRANSACTION")
+	txNested = appendExpect(txNested, xproto.OpenExpectCtxCopyPrev, true, func(p []byte) []byte {
+		p = appendExpect(p, xproto.OpenExpectCtxCopyPrev, true, func(p []byte) []byte {
+			return appendStmts(t, p, "UPDATE foo SET val = 1")
+		})
+		return appendStmts(t, p, "INSERT INTO log VALUES (1)")
+	})
+	txNested = appendStmts(t, txNested, "COMMIT")
+
+	// START TRANSACTION, ExpectOpen, UPDATE, INSERT, ExpectClose, COMMIT, not failing on error
+	txExpectEmpty := appendStmts(t, nil, "START TRANSACTION")
+	txExpectEmpty = appendExpect(txExpectEmpty, xproto.OpenExpectCtxEmpty, false, func(p []byte) []byte {
+		return appendStmts(t, p, "UPDATE foo SET val = 1", "INSERT INTO log VALUES (1)")
+	})
+	txExpectEmpty = appendStmts(t, txExpectEmpty, "COMMIT")
+
+	deadlock := mysqlxError(mysqlx.Error_ERROR, errs.ErLockDeadlock)
+	lockWait := mysqlxError(mysqlx.Error_ERROR, errs.ErLockWaitTimeout)
+
+	tests := []struct {
+		b         []byte
+		result    result
+		retryable bool
+	}{
+		{tx, ok, false},
+		{txExpect, failed(2, deadlock), true},
+		{txExpect, failed(3, deadlock), true},
+		{expectTx, failed(2, deadlock), true},
+		{txNested, failed(3, deadlock), false},
+		{txNested, failed(5, deadlock), true},
+		// Without an expectation block, the statements following the deadlock are autocommitted
+		{tx, failed(1, deadlock), false},
+		{txExpectAfter, failed(2, deadlock), false},
+		{txExpectEmpty, failed(2, deadlock), false},
+		// A lock wait timeout leaves the transaction open, so the COMMIT must be skipped
+		{expectTx, failed(2, lockWait), true},
+		{txExpect, failed(2, lockWait), false},
+		{txExpect, failed(2, mysqlxError(mysqlx.Error_ERROR, errs.ErDupEntry)), false},
+		{txExpect, failed(2, mysqlxError(mysqlx.Error_FATAL, errs.ErLockDeadlock)), false},
+		{autocommit, failed(1, deadlock), false},
+		{twoTx, failed(1, deadlock), false},
+		{nil, result{err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{tx, result{r: []netx.Response{{}}, err: io.EOF}, true},
+		{tx, result{r: []netx.Response{{}, {}}, err: io.EOF}, false},
+		{autocommit, result{r: []netx.Response{{}}, err: io.EOF}, false},
+		{nil, result{err: errors.New("unknown")}, false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if IsRetryable(tt.b, tt.result.r, tt.result.err) != tt.retryable {
+				t.Fatalf("expected retryable %v", tt.retryable)
+			}
+		})
+	}
+}
+
+// discarder is a senderFunc that discards connections on error.
+type discarder struct {
+	senderFunc
+}
+
+func (discarder) DiscardsOnError() bool { return true }
+
+func TestSend(t *testing.T) {
+	deadlock := failed(2, mysqlxError(mysqlx.Error_ERROR, errs.ErLockDeadlock))
+	tx := appendExpect(nil, xproto.OpenExpectCtxCopyPrev, true, func(p []byte) []byte {
+		return appendStmts(t, p, "START TRANSACTION", "UPDATE foo SET val = 1", "COMMIT")
+	})
+
+	tests := []struct {
+		results  []result
+		attempts int
+		sent     int
+	}{
+		{[]result{ok}, 3, 1},
+		{[]result{deadlock, ok}, 3, 2},
+		{[]result{deadlock}, 3, 3},
+		{[]result{deadlock}, 1, 1},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			sf := &senderFunc{results: tt.results}
+			s, err := New(sf, WithAttempts(tt.attempts), WithBackoff(func(int) time.Duration { return 0 }))
+			if err != nil {
+				t.Fatalf("failed to create sender: %s", err)
+			}
+			r, err := s.Send(context.Background(), tx)
+			if sf.sent != tt.sent {
+				t.Fatalf("expected %d attempts, got %d", tt.sent, sf.sent)
+			}
+			last := tt.results[len(tt.results)-1]
+			if err != last.err || len(r) != len(last.r) {
+				t.Fatal("expected result of last attempt")
+			}
+		})
+	}
+}
+
+func TestSendLockWaitTimeout(t *testing.T) {
+	lockWait := failed(2, mysqlxError(mysqlx.Error_ERROR, errs.ErLockWaitTimeout))
+	tx := appendExpect(nil, xproto.OpenExpectCtxCopyPrev, true, func(p []byte) []byte {
+		return appendStmts(t, p, "START TRANSACTION", "UPDATE foo SET val = 1", "COMMIT")
+	})
+	noBackoff := WithBackoff(func(int) time.Duration { return 0 })
+
+	// The transaction is left open on the connection, so only resent if the connection is discarded
+	sf := &senderFunc{results: []result{lockWait, ok}}
+	s, err := New(sf, noBackoff)
+	if err != nil {
+		t.Fatalf("failed to create sender: %s", err)
+	}
+	if s.Send(context.Background(), tx); sf.sent != 1 {
+		t.Fatalf("expected 1 attempt, got %d", sf.sent)
+	}
+
+	d := &discarder{senderFunc{results: []result{lockWait, ok}}}
+	if s, err = New(d, noBackoff); err != nil {
+		t.Fatalf("failed to create sender: %s", err)
+	}
+	if s.Send(context.Background(), tx); d.sent != 2 {
+		t.Fatalf("expected 2 attempts, got %d", d.sent)
+	}
+}
+
+func TestExponential(t *testing.T) {
+	for _, tt := range []struct{ base, max time.Duration }{
+		{10 * time.Millisecond, time.Second},
+		// base shifted left overflows before reaching max
+		{10 * time.Second, time.Minute},
+	} {
+		b := Exponential(tt.base, tt.max)
+		for attempt := 1; attempt < 100; attempt++ {
+			if d := b(attempt); d < 0 || d > tt.max {
+				t.Fatalf("backoff out of range: %s", d)
+			}
+		}
+	}
+}
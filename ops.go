@@ -0,0 +1,103 @@
+package xtorm
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	"github.com/renthraysk/xtorm/netx"
+)
+
+// Op records a Builder call, and the range of messages it appended to the unit of work.
+type Op struct {
+	// Kind is the name of the Builder method, eg "Insert", "Update", "Execute"
+	Kind string
+	// Table the operation acts upon, if any
+	Table string
+	// Label supplied via Builder.Label(), if any
+	Label string
+	// Messages appended are [Start, End)
+	Start, End int
+}
+
+func (o Op) String() string {
+	s := o.Kind
+	if o.Table != "" {
+		s += " " + strconv.Quote(o.Table)
+	}
+	if o.Label != "" {
+		s += " (" + o.Label + ")"
+	}
+	return s
+}
+
+// OpError is returned from Send when the server responded with an error, identifying the operation that caused it.
+type OpError struct {
+	// Op that appended the failing message
+	Op Op
+	// Index of the failing message within the unit of work, and of its response
+	Index int
+	// Err is the error the server responded with, typically a *connection.MySqlXError
+	Err error
+}
+
+func (e *OpError) Error() string {
+	return "message " + strconv.Itoa(e.Index) + " of " + e.Op.String() + " failed: " + e.Err.Error()
+}
+
+func (e *OpError) Unwrap() error { return e.Err }
+
+// Label sets the label of the next operation appended, to identify it in any resulting OpError.
+func (b *Builder) Label(label string) {
+	if b.disabled {
+		panic("Label called on non child")
+	}
+	b.label = label
+}
+
+// Ops returns the operations appended to the builder.
+func (b *Builder) Ops() []Op {
+	return b.ops
+}
+
+// opAt returns the operation that appended the message at index i.
+func (b *Builder) opAt(i int) Op {
+	for _, op := range b.ops {
+		if i >= op.Start && i < op.End {
+			return op
+		}
+	}
+	return Op{Start: i, End: i + 1}
+}
+
+// count returns the number of messages appended, scanning any appended since last called.
+func (b *Builder) count() int {
+	for b.scanned+4 <= len(b.buf) {
+		b.scanned += 4 + int(binary.LittleEndian.Uint32(b.buf[b.scanned:]))
+		b.messages++
+	}
+	return b.messages
+}
+
+// rescanned resyncs count() after the last message of buf has been rewritten in place, changing its length.
+func (b *Builder) rescanned() {
+	b.scanned = len(b.buf)
+}
+
+// record records an operation of kind upon table, that appended messages from start.
+func (b *Builder) record(kind, table string, start int) {
+	if b.err != nil {
+		return
+	}
+	b.ops = append(b.ops, Op{Kind: kind, Table: table, Label: b.label, Start: start, End: b.count()})
+	b.label = ""
+}
+
+// opError returns an OpError for the first response with an error, or err if none.
+func (b *Builder) opError(r []netx.Response, err error) error {
+	for i, rr := range r {
+		if rr.Err != nil {
+			return &OpError{Op: b.opAt(i), Index: i, Err: rr.Err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package xtorm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/renthraysk/xtorm/netx"
+)
+
+func TestOpError(t *testing.T) {
+	x := New(bufferSize)
+	x.ExpectFailOnError(OpenExpectCtxEmpty, func(b *Builder) error {
+		b.Tx(IsolationLevelDefault, func(b *Builder) error {
+			b.Delete("foo", nil)
+			b.Label("first row")
+			b.InsertRow("foo", []string{"id"}, []interface{}{1})
+			return nil
+		})
+		return nil
+	})
+
+	ops := x.Ops()
+	kinds := make([]string, len(ops))
+	for i, op := range ops {
+		kinds[i] = op.Kind
+	}
+	if !reflect.DeepEqual(kinds, []string{"ExpectOpen", "Tx", "Delete", "InsertRow", "Commit", "ExpectClose"}) {
+		t.Fatalf("unexpected ops %v", kinds)
+	}
+
+	failed := errors.New("duplicate key")
+	// InsertRow appends an Insert & a StmtExecute, fail the StmtExecute
+	responses := make([]netx.Response, 8)
+	responses[4].Err = failed
+	responses[5].Err = errors.New("expectation failed")
+	err := x.opError(responses, nil)
+	var opErr *OpError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("expected OpError, got %v", err)
+	}
+	if opErr.Index != 4 || opErr.Op.Kind != "InsertRow" || opErr.Op.Table != "foo" || opErr.Op.Label != "first row" {
+		t.Fatalf("unexpected OpError %+v", opErr)
+	}
+	if !errors.Is(err, failed) {
+		t.Fatal("expected OpError to wrap response error")
+	}
+	if err := x.opError(make([]netx.Response, 8), nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
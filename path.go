@@ -0,0 +1,57 @@
+package xtorm
+
+import (
+	"strings"
+
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+// DocumentPath is a path into a JSON document, or JSON column.
+type DocumentPath = xproto.DocumentPath
+
+// PathItem is a single step of a DocumentPath.
+type PathItem = xproto.DocumentPathItem
+
+// Path returns a DocumentPath of items, eg Path(Member("a"), Index(0)) for $.a[0]
+func Path(items ...PathItem) DocumentPath {
+	return DocumentPath(items)
+}
+
+// Member steps into the object member name, as with .name
+func Member(name string) PathItem {
+	return PathItem{Type: xproto.DocumentPathMember, Value: name}
+}
+
+// MemberAsterisk steps into every member of an object, as with .*
+func MemberAsterisk() PathItem {
+	return PathItem{Type: xproto.DocumentPathMemberAsterisk}
+}
+
+// Index steps into the array element at index i, as with [i]
+func Index(i uint32) PathItem {
+	return PathItem{Type: xproto.DocumentPathArrayIndex, Index: i}
+}
+
+// IndexAsterisk steps into every element of an array, as with [*]
+func IndexAsterisk() PathItem {
+	return PathItem{Type: xproto.DocumentPathArrayIndexAsterisk}
+}
+
+// DoubleAsterisk steps into every descendant, as with **
+func DoubleAsterisk() PathItem {
+	return PathItem{Type: xproto.DocumentPathDoubleAsterisk}
+}
+
+// Field returns the path to a field of a document, for use in expressions, with nested fields separated by dots,
+// eg Eq(Field("address.city"), "London")
+func Field(name string) DocumentPath {
+	path := make(DocumentPath, 0, 1+strings.Count(name, "."))
+	for {
+		i := strings.IndexByte(name, '.')
+		if i < 0 {
+			return append(path, Member(name))
+		}
+		path = append(path, Member(name[:i]))
+		name = name[i+1:]
+	}
+}
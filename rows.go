@@ -0,0 +1,224 @@
+package xtorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/renthraysk/xtorm/netx"
+	"github.com/renthraysk/xtorm/xdecode"
+)
+
+// Rows iterates over the rows of a result set, decoding them into Go values.
+type Rows struct {
+	rs  *netx.ResultSet
+	row int
+
+	// Column to struct field mapping of the last struct type scanned into
+	structType reflect.Type
+	fields     [][]int
+}
+
+// NewRows returns a Rows positioned before the first row of the result set.
+func NewRows(rs *netx.ResultSet) *Rows {
+	return &Rows{rs: rs, row: -1}
+}
+
+// Columns returns the names of the columns of the result set.
+func (r *Rows) Columns() []string {
+	s := make([]string, len(r.rs.Columns))
+	for i, c := range r.rs.Columns {
+		s[i] = string(c.GetName())
+	}
+	return s
+}
+
+// Len returns the number of rows in the result set.
+func (r *Rows) Len() int {
+	return len(r.rs.Rows)
+}
+
+// Next advances to the next row, returning false when there are no more rows.
+func (r *Rows) Next() bool {
+	if r.row < len(r.rs.Rows) {
+		r.row++
+	}
+	return r.row < len(r.rs.Rows)
+}
+
+func (r *Rows) current() (netx.Row, error) {
+	if r.row < 0 || r.row >= len(r.rs.Rows) {
+		return nil, errors.New("Scan called without a successful call to Next")
+	}
+	return r.rs.Rows[r.row], nil
+}
+
+// Scan decodes the columns of the current row into the values pointed at by dest.
+// The number of values in dest must be the same as the number of columns.
+func (r *Rows) Scan(dest ...interface{}) error {
+	row, err := r.current()
+	if err != nil {
+		return err
+	}
+	if len(dest) != len(r.rs.Columns) {
+		return fmt.Errorf("expected %d destination arguments in Scan, got %d", len(r.rs.Columns), len(dest))
+	}
+	for i, d := range dest {
+		if err := r.scanColumn(row, i, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Rows) scanColumn(row netx.Row, i int, dest interface{}) error {
+	if i >= len(row) {
+		return fmt.Errorf("row has %d fields, expected %d", len(row), len(r.rs.Columns))
+	}
+	v, err := xdecode.Field(row[i], r.rs.Columns[i])
+	if err != nil {
+		return fmt.Errorf("column %d %q: %w", i, r.rs.Columns[i].GetName(), err)
+	}
+	if err := assign(dest, v); err != nil {
+		return fmt.Errorf("column %d %q: %w", i, r.rs.Columns[i].GetName(), err)
+	}
+	return nil
+}
+
+// ScanStruct decodes the current row into the struct pointed to by v, mapping columns to fields by their xtorm tag,
+// or name if untagged. Fields tagged with "-" are ignored, as are columns with no matching field.
+func (r *Rows) ScanStruct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ScanStruct expects a non nil pointer to a struct, got %T", v)
+	}
+	row, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.scanStruct(row, rv.Elem())
+}
+
+func (r *Rows) scanStruct(row netx.Row, rv reflect.Value) error {
+	if r.structType != rv.Type() {
+		p := planFor(rv.Type())
+		r.fields = make([][]int, len(r.rs.Columns))
+		for i, c := range r.rs.Columns {
+			r.fields[i] = p.lookup(string(c.GetName()))
+		}
+		r.structType = rv.Type()
+	}
+	for i, index := range r.fields {
+		if index == nil {
+			continue
+		}
+		if err := r.scanColumn(row, i, rv.FieldByIndex(index).Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScanAll decodes the current and all remaining rows, appending them to the slice pointed at by v.
+// The slice element may be a struct, or a pointer to a struct, which are scanned as with ScanStruct.
+// Otherwise the result set must have a single column, which is scanned as with Scan.
+func (r *Rows) ScanAll(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("ScanAll expects a non nil pointer to a slice, got %T", v)
+	}
+	s := rv.Elem()
+	t := s.Type().Elem()
+	isPtr := t.Kind() == reflect.Ptr
+	if isPtr {
+		t = t.Elem()
+	}
+	isStruct := t.Kind() == reflect.Struct && !reflect.PtrTo(t).Implements(scannerType) && t != timeType
+	if !isStruct && len(r.rs.Columns) != 1 {
+		return fmt.Errorf("ScanAll into %s requires a single column, got %d", s.Type(), len(r.rs.Columns))
+	}
+	if r.row < 0 {
+		r.row = 0
+	}
+	for ; r.row < len(r.rs.Rows); r.row++ {
+		row := r.rs.Rows[r.row]
+		e := reflect.New(t)
+		var err error
+		if isStruct {
+			err = r.scanStruct(row, e.Elem())
+		} else {
+			err = r.scanColumn(row, 0, e.Interface())
+		}
+		if err != nil {
+			return fmt.Errorf("row %d: %w", r.row, err)
+		}
+		if !isPtr {
+			e = e.Elem()
+		}
+		s = reflect.Append(s, e)
+	}
+	rv.Elem().Set(s)
+	return nil
+}
+
+// structPlan maps column names to the index of struct fields.
+type structPlan struct {
+	fields map[string][]int
+	// folded maps lowercased names to the first field in declaration order
+	folded map[string][]int
+}
+
+func (p *structPlan) lookup(name string) []int {
+	if index, ok := p.fields[name]; ok {
+		return index
+	}
+	return p.folded[strings.ToLower(name)]
+}
+
+var structPlans sync.Map // map[reflect.Type]*structPlan
+
+func planFor(t reflect.Type) *structPlan {
+	if p, ok := structPlans.Load(t); ok {
+		return p.(*structPlan)
+	}
+	p := &structPlan{fields: make(map[string][]int), folded: make(map[string][]int)}
+	p.add(t, nil)
+	pp, _ := structPlans.LoadOrStore(t, p)
+	return pp.(*structPlan)
+}
+
+func (p *structPlan) add(t reflect.Type, index []int) {
+	var embedded []int
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("xtorm")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, i)
+			continue
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		name := f.Name
+		if tag != "" {
+			name = tag
+		}
+		if _, ok := p.fields[name]; !ok {
+			p.fields[name] = append(index[:len(index):len(index)], i)
+		}
+		if lower := strings.ToLower(name); p.folded[lower] == nil {
+			p.folded[lower] = p.fields[name]
+		}
+	}
+	// Embedded struct fields after, so shallower fields take precedence as with Go's field promotion
+	for _, i := range embedded {
+		p.add(t.Field(i).Type, append(index[:len(index):len(index)], i))
+	}
+}
@@ -0,0 +1,113 @@
+package xtorm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRowsScan(t *testing.T) {
+	var id int
+	var name string
+	var nickname *string
+
+	rows := NewRows(testResultSet())
+	if err := rows.Scan(&id); err == nil {
+		t.Fatal("expected error scanning before Next")
+	}
+	if !rows.Next() {
+		t.Fatal("expected row")
+	}
+	if err := rows.Scan(&id, &name); err == nil {
+		t.Fatal("expected error scanning with too few destinations")
+	}
+	if err := rows.Scan(&id, &name, &nickname); err != nil {
+		t.Fatalf("failed to scan: %s", err)
+	}
+	if id != 1 || name != "alice" || nickname != nil {
+		t.Fatalf("unexpected values %d %q %v", id, name, nickname)
+	}
+	if !rows.Next() {
+		t.Fatal("expected row")
+	}
+	if err := rows.Scan(&id, &name, &nickname); err != nil {
+		t.Fatalf("failed to scan: %s", err)
+	}
+	if id != 2 || name != "bob" || nickname == nil || *nickname != "bobby" {
+		t.Fatalf("unexpected values %d %q %v", id, name, nickname)
+	}
+	if rows.Next() {
+		t.Fatal("expected no more rows")
+	}
+}
+
+func TestRowsScanStruct(t *testing.T) {
+	var u testUser
+
+	rows := NewRows(testResultSet())
+	rows.Next()
+	rows.Next()
+	if err := rows.ScanStruct(&u); err != nil {
+		t.Fatalf("failed to scan struct: %s", err)
+	}
+	if u.ID != 2 || u.Name != "bob" || u.Nickname == nil || *u.Nickname != "bobby" {
+		t.Fatalf("unexpected struct %+v", u)
+	}
+}
+
+func TestStructPlanFold(t *testing.T) {
+	type folded struct {
+		Name  string
+		NAME  string
+		Other string `xtorm:"nAmE"`
+	}
+
+	p := planFor(reflect.TypeOf(folded{}))
+	tests := []struct {
+		name  string
+		index int
+	}{
+		{"Name", 0},
+		{"NAME", 1},
+		{"nAmE", 2},
+		{"name", 0},
+		{"naME", 0},
+	}
+	for _, tt := range tests {
+		if index := p.lookup(tt.name); len(index) != 1 || index[0] != tt.index {
+			t.Errorf("lookup(%q): expected field %d, got %v", tt.name, tt.index, index)
+		}
+	}
+	if index := p.lookup("missing"); index != nil {
+		t.Errorf("lookup(missing): expected nil, got %v", index)
+	}
+}
+
+func TestRowsScanAll(t *testing.T) {
+	var users []*testUser
+
+	if err := NewRows(testResultSet()).ScanAll(&users); err != nil {
+		t.Fatalf("failed to scan all: %s", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+	if users[0].ID != 1 || users[0].Name != "alice" || users[0].Nickname != nil {
+		t.Fatalf("unexpected struct %+v", users[0])
+	}
+	if users[1].ID != 2 || users[1].Name != "bob" || *users[1].Nickname != "bobby" {
+		t.Fatalf("unexpected struct %+v", users[1])
+	}
+
+	var ids []int64
+	rs := testResultSet()
+	rs.Columns = rs.Columns[:1]
+	if err := NewRows(rs).ScanAll(&ids); err != nil {
+		t.Fatalf("failed to scan all: %s", err)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2}) {
+		t.Fatalf("unexpected ids %v", ids)
+	}
+	if err := NewRows(testResultSet()).ScanAll(&ids); err == nil {
+		t.Fatal("expected error scanning multiple columns into []int64")
+	}
+}
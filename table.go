@@ -0,0 +1,26 @@
+package xtorm
+
+import (
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+// Table is a reference to a table, in Schema, or the session's default schema if empty.
+type Table struct {
+	Schema string
+	Name   string
+}
+
+// String returns the, possibly schema qualified, name of the table.
+func (t Table) String() string {
+	if t.Schema == "" {
+		return t.Name
+	}
+	return t.Schema + "." + t.Name
+}
+
+// Column returns an expression of the column name, qualified by the table, and schema if any.
+func (t Table) Column(name string) exprFunc {
+	return func(p []byte, tag uint8) ([]byte, error) {
+		return xproto.AppendExprQualifiedColumn(p, tag, t.Schema, t.Name, name)
+	}
+}
@@ -0,0 +1,51 @@
+package xtorm
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_crud"
+)
+
+func TestTableVariants(t *testing.T) {
+	foo := Table{Schema: "shop", Name: "foo"}
+
+	x := New(bufferSize)
+	x.InsertTable(foo, []string{"id"}, [][]interface{}{{1}})
+	x.UpdateTable(foo, Eq(foo.Column("id"), 1), map[string]interface{}{"val": 2})
+	x.DeleteTable(foo, nil)
+	x.FindTable(foo, []string{"id"}, nil)
+	x.FindTableF(foo, nil, func(f *Find) error {
+		f.Limit(1, 0)
+		return nil
+	})
+	x.Find("foo", nil, nil)
+	if x.err != nil {
+		t.Fatalf("failed to build: %s", x.err)
+	}
+
+	for i, op := range x.Ops() {
+		expected := "shop.foo"
+		if i == 5 {
+			expected = "foo"
+		}
+		if op.Table != expected {
+			t.Fatalf("expected op %d upon %s, got %s", i, expected, op.Table)
+		}
+	}
+
+	var f mysqlx_crud.Find
+	if err := proto.Unmarshal(testMessage(x.buf, 3)[5:], &f); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if f.GetCollection().GetSchema() != "shop" || f.GetCollection().GetName() != "foo" {
+		t.Fatalf("unexpected find collection %v", f.GetCollection())
+	}
+	if err := proto.Unmarshal(testMessage(x.buf, 5)[5:], &f); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if f.GetCollection().GetSchema() != "" || f.GetCollection().GetName() != "foo" {
+		t.Fatalf("unexpected find collection %v", f.GetCollection())
+	}
+}
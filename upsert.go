@@ -0,0 +1,163 @@
+package xtorm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+type upsertAction uint8
+
+const (
+	upsertValues upsertAction = iota
+	upsertKeep
+	upsertExpr
+)
+
+// UpsertPolicy determines how a column of an existing row is updated, when an upserted row collides with it on a
+// primary or unique key.
+type UpsertPolicy struct {
+	action upsertAction
+	expr   string
+	args   []interface{}
+}
+
+var (
+	// UpsertValues updates the column with the value from the upserted row, as with col = VALUES(col).
+	// The policy of columns without one.
+	UpsertValues = UpsertPolicy{action: upsertValues}
+	// UpsertKeep keeps the existing value of the column.
+	UpsertKeep = UpsertPolicy{action: upsertKeep}
+)
+
+// UpsertExpr updates the column with the SQL expression expr, with ? placeholders for args.
+// eg UpsertExpr("hits + ?", 1), or UpsertExpr("NOW()")
+func UpsertExpr(expr string, args ...interface{}) UpsertPolicy {
+	return UpsertPolicy{action: upsertExpr, expr: expr, args: args}
+}
+
+// Upsert inserts rows of data into the columns of table, updating existing rows with a duplicate primary or unique key
+// according to policy, keyed by column name. Columns not inserted may also be updated by an UpsertExpr() policy.
+// The X Protocol only supports upserting documents, so this is executed as an INSERT ... ON DUPLICATE KEY UPDATE
+// statement, and values are restricted to scalars.
+func (b *Builder) Upsert(table Table, columns []string, data [][]interface{}, policy map[string]UpsertPolicy) {
+	if b.disabled {
+		panic("Upsert called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	stmt, args, err := upsertStmt(table, columns, data, policy)
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.buf, b.err = xproto.StmtExecute(b.buf, stmt, args)
+	b.record("Upsert", table.String(), start)
+}
+
+// UpsertDocuments inserts documents into collection, replacing existing documents with the same _id.
+// Documents are as accepted by Collection.Add(), and must include an _id.
+func (b *Builder) UpsertDocuments(collection Table, docs ...interface{}) {
+	b.insertDocuments("UpsertDocuments", collection, docs, true)
+}
+
+func upsertStmt(table Table, columns []string, data [][]interface{}, policy map[string]UpsertPolicy) (string, []interface{}, error) {
+	if len(columns) == 0 || len(data) == 0 {
+		return "", nil, errors.New("upsert requires columns and at least one row")
+	}
+	args := make([]interface{}, 0, len(columns)*len(data))
+
+	s := append(make([]byte, 0, 128), "INSERT INTO "...)
+	if table.Schema != "" {
+		s = appendIdentifier(s, table.Schema)
+		s = append(s, '.')
+	}
+	s = appendIdentifier(s, table.Name)
+	s = append(s, " ("...)
+	inserted := make(map[string]bool, len(columns))
+	for i, column := range columns {
+		if i > 0 {
+			s = append(s, ',')
+		}
+		s = appendIdentifier(s, column)
+		inserted[column] = true
+	}
+	s = append(s, ") VALUES "...)
+	for i, row := range data {
+		if len(row) != len(columns) {
+			return "", nil, fmt.Errorf("unexpected number of values in row %d, expected %d, got %d", i, len(columns), len(row))
+		}
+		if i > 0 {
+			s = append(s, ',')
+		}
+		s = append(s, '(')
+		for j := range row {
+			if j > 0 {
+				s = append(s, ',')
+			}
+			s = append(s, '?')
+		}
+		s = append(s, ')')
+		args = append(args, row...)
+	}
+
+	// Inserted columns in order, followed by those only updated, sorted for a deterministic statement
+	var others []string
+	for column := range policy {
+		if !inserted[column] {
+			others = append(others, column)
+		}
+	}
+	sort.Strings(others)
+
+	order := append(append(make([]string, 0, len(columns)+len(others)), columns...), others...)
+
+	s = append(s, " ON DUPLICATE KEY UPDATE "...)
+	n := len(s)
+	for _, column := range order {
+		p := policy[column]
+		if p.action == upsertKeep {
+			continue
+		}
+		if len(s) > n {
+			s = append(s, ',')
+		}
+		s = appendIdentifier(s, column)
+		s = append(s, '=')
+		switch p.action {
+		case upsertValues:
+			if !inserted[column] {
+				return "", nil, fmt.Errorf("upsert values policy for column %q, which is not inserted", column)
+			}
+			s = append(s, "VALUES("...)
+			s = appendIdentifier(s, column)
+			s = append(s, ')')
+		case upsertExpr:
+			s = append(s, p.expr...)
+			args = append(args, p.args...)
+		}
+	}
+	// Keeping all existing values, still requires an assignment
+	if len(s) == n {
+		s = appendIdentifier(s, columns[0])
+		s = append(s, '=')
+		s = appendIdentifier(s, columns[0])
+	}
+	return string(s), args, nil
+}
+
+// appendIdentifier appends s as a backtick quoted SQL identifier.
+func appendIdentifier(p []byte, s string) []byte {
+	p = append(p, '`')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '`' {
+			p = append(p, '`')
+		}
+		p = append(p, s[i])
+	}
+	return append(p, '`')
+}
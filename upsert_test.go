@@ -0,0 +1,89 @@
+package xtorm
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_crud"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_sql"
+)
+
+func TestUpsert(t *testing.T) {
+	tests := []struct {
+		table   Table
+		columns []string
+		data    [][]interface{}
+		policy  map[string]UpsertPolicy
+		stmt    string
+		args    int
+	}{
+		{Table{Name: "foo"}, []string{"id", "val"}, [][]interface{}{{1, "one"}, {2, "two"}}, nil,
+			"INSERT INTO `foo` (`id`,`val`) VALUES (?,?),(?,?) ON DUPLICATE KEY UPDATE `id`=VALUES(`id`),`val`=VALUES(`val`)", 4},
+		{Table{Schema: "bar", Name: "foo"}, []string{"id", "val"}, [][]interface{}{{1, "one"}},
+			map[string]UpsertPolicy{"id": UpsertKeep, "hits": UpsertExpr("`hits`+?", 1), "modified": UpsertExpr("NOW()")},
+			"INSERT INTO `bar`.`foo` (`id`,`val`) VALUES (?,?) ON DUPLICATE KEY UPDATE `val`=VALUES(`val`),`hits`=`hits`+?,`modified`=NOW()", 3},
+		{Table{Name: "fo`o"}, []string{"id"}, [][]interface{}{{1}}, map[string]UpsertPolicy{"id": UpsertKeep},
+			"INSERT INTO `fo``o` (`id`) VALUES (?) ON DUPLICATE KEY UPDATE `id`=`id`", 1},
+	}
+
+	for _, tt := range tests {
+		x := New(bufferSize)
+		var s mysqlx_sql.StmtExecute
+
+		x.ExpectFailOnError(OpenExpectCtxEmpty, func(b *Builder) error {
+			b.Upsert(tt.table, tt.columns, tt.data, tt.policy)
+			return nil
+		})
+		if x.err != nil {
+			t.Fatalf("failed to upsert: %s", x.err)
+		}
+		ops := x.Ops()
+		if len(ops) != 3 || ops[1].Kind != "Upsert" {
+			t.Fatalf("unexpected ops %v", ops)
+		}
+		b := testMessage(x.buf, ops[1].Start)
+		if b[4] != byte(mysqlx.ClientMessages_SQL_STMT_EXECUTE) {
+			t.Fatal("incorrect clientmessage type")
+		}
+		if err := proto.Unmarshal(b[5:], &s); err != nil {
+			t.Fatalf("unmarshal failed: %s", err)
+		}
+		if string(s.GetStmt()) != tt.stmt {
+			t.Fatalf("expected statement %q, got %q", tt.stmt, s.GetStmt())
+		}
+		if len(s.GetArgs()) != tt.args {
+			t.Fatalf("expected %d args, got %d", tt.args, len(s.GetArgs()))
+		}
+	}
+
+	x := New(bufferSize)
+	x.ExpectFailOnError(OpenExpectCtxEmpty, func(b *Builder) error {
+		b.Upsert(Table{Name: "foo"}, []string{"id"}, [][]interface{}{{1}}, map[string]UpsertPolicy{"val": UpsertValues})
+		return nil
+	})
+	if x.err == nil {
+		t.Fatal("expected error for values policy of column not inserted")
+	}
+}
+
+func TestUpsertDocuments(t *testing.T) {
+	x := New(bufferSize)
+	var i mysqlx_crud.Insert
+
+	x.ExpectFailOnError(OpenExpectCtxEmpty, func(b *Builder) error {
+		b.UpsertDocuments(Table{Name: "docs"}, JSONString(`{"_id": "1"}`), JSONString(`{"_id": "2"}`))
+		return nil
+	})
+	if x.err != nil {
+		t.Fatalf("failed to upsert documents: %s", x.err)
+	}
+	b := testMessage(x.buf, x.Ops()[1].Start)
+	if err := proto.Unmarshal(b[5:], &i); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if i.GetDataModel() != mysqlx_crud.DataModel_DOCUMENT || !i.GetUpsert() || len(i.GetRow()) != 2 {
+		t.Fatalf("unexpected insert %v", &i)
+	}
+}
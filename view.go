@@ -0,0 +1,133 @@
+package xtorm
+
+import (
+	"errors"
+
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+type ViewAlgorithm uint8
+
+const (
+	// AlgorithmUndefined lets the server choose between merge & temptable
+	AlgorithmUndefined = ViewAlgorithm(xproto.ViewAlgorithmUndefined)
+	// AlgorithmMerge merges the view's statement into the statement referring to it
+	AlgorithmMerge = ViewAlgorithm(xproto.ViewAlgorithmMerge)
+	// AlgorithmTempTable materialises the view into a temporary table
+	AlgorithmTempTable = ViewAlgorithm(xproto.ViewAlgorithmTempTable)
+)
+
+type ViewSecurity uint8
+
+const (
+	// SecurityInvoker checks privileges of the user referring to the view
+	SecurityInvoker = ViewSecurity(xproto.ViewSecurityInvoker)
+	// SecurityDefiner checks privileges of the view's definer
+	SecurityDefiner = ViewSecurity(xproto.ViewSecurityDefiner)
+)
+
+type ViewCheckOption uint8
+
+const (
+	// CheckLocal checks rows written through the view satisfy its criteria
+	CheckLocal = ViewCheckOption(xproto.ViewCheckLocal)
+	// CheckCascaded checks rows written through the view satisfy its criteria, and those of underlying views
+	CheckCascaded = ViewCheckOption(xproto.ViewCheckCascaded)
+)
+
+// ViewOptions are the options of a view, zero values are left to the server's defaults, or unmodified.
+type ViewOptions struct {
+	// Definer, as 'user'@'host'
+	Definer   string
+	Algorithm ViewAlgorithm
+	Security  ViewSecurity
+	Check     ViewCheckOption
+	// Columns names the columns of the view, overriding those of the Find
+	Columns []string
+}
+
+func (o ViewOptions) view() xproto.View {
+	return xproto.View{
+		Definer:   o.Definer,
+		Algorithm: xproto.ViewAlgorithm(o.Algorithm),
+		Security:  xproto.ViewSecurity(o.Security),
+		Check:     xproto.ViewCheckOption(o.Check),
+		Columns:   o.Columns,
+	}
+}
+
+// viewFind appends the single Find appended by f, returning the position in buf it was appended at, and the index of
+// its recorded operation.
+func (b *Builder) viewFind(kind string, f func(b *Builder) error) (n int, op int) {
+	start := b.count()
+	n = len(b.buf)
+	op = len(b.ops)
+	b.call(f)
+	if b.err == nil && b.count() != start+1 {
+		b.err = errors.New(kind + " expects a single Find")
+	}
+	return n, op
+}
+
+// CreateView creates view, defined by the single Find appended by f, replacing any existing view if replace is true.
+func (b *Builder) CreateView(view Table, options ViewOptions, replace bool, f func(b *Builder) error) {
+	if b.disabled {
+		panic("CreateView called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	n, op := b.viewFind("CreateView", f)
+	if b.err != nil {
+		return
+	}
+	b.buf, b.err = xproto.CreateView(b.buf, n, view.Schema, view.Name, options.view(), replace)
+	b.rescanned()
+	b.relabel(op, "CreateView", view)
+}
+
+// ModifyView modifies the options of view, and if f is not nil, its definition to the single Find appended by f.
+func (b *Builder) ModifyView(view Table, options ViewOptions, f func(b *Builder) error) {
+	if b.disabled {
+		panic("ModifyView called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	n, op := len(b.buf), len(b.ops)
+	if f != nil {
+		n, op = b.viewFind("ModifyView", f)
+		if b.err != nil {
+			return
+		}
+	}
+	b.buf, b.err = xproto.ModifyView(b.buf, n, view.Schema, view.Name, options.view())
+	if f == nil {
+		b.record("ModifyView", view.String(), start)
+		return
+	}
+	b.rescanned()
+	b.relabel(op, "ModifyView", view)
+}
+
+// DropView drops view, ignoring a view that does not exist if ifExists is true.
+func (b *Builder) DropView(view Table, ifExists bool) {
+	if b.disabled {
+		panic("DropView called on non child")
+	}
+	if b.err != nil {
+		return
+	}
+	start := b.count()
+	b.buf = xproto.DropView(b.buf, view.Schema, view.Name, ifExists)
+	b.record("DropView", view.String(), start)
+}
+
+// relabel changes the kind and table of the operation recorded at index i, for a message converted into another.
+func (b *Builder) relabel(i int, kind string, table Table) {
+	if i < len(b.ops) {
+		b.ops[i].Kind = kind
+		b.ops[i].Table = table.String()
+	}
+}
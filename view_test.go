@@ -0,0 +1,109 @@
+package xtorm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_crud"
+)
+
+func TestView(t *testing.T) {
+	report := Table{Schema: "reports", Name: "big_foo"}
+
+	x := New(bufferSize)
+	x.CreateView(report, ViewOptions{Algorithm: AlgorithmMerge, Check: CheckLocal, Columns: []string{"a", "b"}}, true,
+		func(b *Builder) error {
+			b.Find("foo", []string{"id", "val"}, Gt(Column("id"), 10))
+			return nil
+		})
+	x.ModifyView(report, ViewOptions{Security: SecurityInvoker, Definer: "'root'@'localhost'"}, nil)
+	x.DropView(report, true)
+	if x.err != nil {
+		t.Fatalf("failed to build: %s", x.err)
+	}
+	ops := x.Ops()
+	if len(ops) != 3 || ops[0].Kind != "CreateView" || ops[0].Table != "reports.big_foo" || ops[1].Kind != "ModifyView" ||
+		ops[2].Kind != "DropView" {
+		t.Fatalf("unexpected ops %v", ops)
+	}
+
+	var c mysqlx_crud.CreateView
+	b := testMessage(x.buf, 0)
+	if b[4] != byte(mysqlx.ClientMessages_CRUD_CREATE_VIEW) {
+		t.Fatalf("unexpected message type %d", b[4])
+	}
+	if err := proto.Unmarshal(b[5:], &c); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if c.GetCollection().GetName() != "big_foo" || c.GetAlgorithm() != mysqlx_crud.ViewAlgorithm_MERGE ||
+		c.GetCheck() != mysqlx_crud.ViewCheckOption_LOCAL || len(c.GetColumn()) != 2 || !c.GetReplaceExisting() {
+		t.Fatalf("unexpected create view %v", &c)
+	}
+	if c.GetStmt().GetCollection().GetName() != "foo" || len(c.GetStmt().GetProjection()) != 2 {
+		t.Fatalf("unexpected create view statement %v", c.GetStmt())
+	}
+
+	var m mysqlx_crud.ModifyView
+	b = testMessage(x.buf, 1)
+	if b[4] != byte(mysqlx.ClientMessages_CRUD_MODIFY_VIEW) {
+		t.Fatalf("unexpected message type %d", b[4])
+	}
+	if err := proto.Unmarshal(b[5:], &m); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if m.GetSecurity() != mysqlx_crud.ViewSqlSecurity_INVOKER || m.GetDefiner() != "'root'@'localhost'" || m.Stmt != nil {
+		t.Fatalf("unexpected modify view %v", &m)
+	}
+
+	var d mysqlx_crud.DropView
+	b = testMessage(x.buf, 2)
+	if b[4] != byte(mysqlx.ClientMessages_CRUD_DROP_VIEW) {
+		t.Fatalf("unexpected message type %d", b[4])
+	}
+	if err := proto.Unmarshal(b[5:], &d); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if d.GetCollection().GetSchema() != "reports" || !d.GetIfExists() {
+		t.Fatalf("unexpected drop view %v", &d)
+	}
+
+	x = New(bufferSize)
+	x.CreateView(report, ViewOptions{}, false, func(b *Builder) error {
+		b.Delete("foo", nil)
+		return nil
+	})
+	if x.err == nil {
+		t.Fatal("expected error creating view from delete")
+	}
+}
+
+func TestViewOps(t *testing.T) {
+	report := Table{Schema: "reports", Name: "big_foo"}
+	find := func(b *Builder) error {
+		b.Find("foo", []string{"id", "val"}, Gt(Column("id"), 10))
+		return nil
+	}
+
+	x := New(bufferSize)
+	x.CreateView(report, ViewOptions{}, false, find)
+	x.ModifyView(report, ViewOptions{Algorithm: AlgorithmTempTable}, find)
+	ref := x.Ref()
+	x.DropView(report, true)
+	if x.err != nil {
+		t.Fatalf("failed to build: %s", x.err)
+	}
+
+	expected := []string{"CreateView 0-1", "ModifyView 1-2", "DropView 2-3"}
+	if ranges := testOpRanges(t, &x.Builder); !reflect.DeepEqual(ranges, expected) {
+		t.Fatalf("expected ops %v, got %v", expected, ranges)
+	}
+	if ref != 2 || x.Ref() != 3 {
+		t.Fatalf("unexpected refs %d, %d", ref, x.Ref())
+	}
+	if b := testMessage(x.buf, 2); b[4] != byte(mysqlx.ClientMessages_CRUD_DROP_VIEW) {
+		t.Fatalf("unexpected message type %d", b[4])
+	}
+}
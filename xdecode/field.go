@@ -0,0 +1,287 @@
+// Package xdecode decodes the values mysql's X Protocol sends back to the client, either the fields of a
+// Mysqlx.Resultset.Row or the Mysqlx.Datatypes Scalar & Any protobufs, into Go values.
+package xdecode
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/renthraysk/xtorm/collation"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_resultset"
+	"github.com/renthraysk/xtorm/types"
+)
+
+var (
+	// ErrNull is returned by the typed decoders when the field is NULL
+	ErrNull = errors.New("NULL value")
+	// ErrMalformed is returned when the field is not correctly encoded for its type
+	ErrMalformed = errors.New("malformed field")
+)
+
+const (
+	// flagRightPad is ColumnMetaData flag for BYTES indicating the value should be right padded to .length
+	flagRightPad = 0x0001
+)
+
+// Field decodes a field of a Row, given the ColumnMetaData of its column.
+// NULL is returned as nil, otherwise the Go type returned depends on the column type.
+//
+//	SINT     int64
+//	UINT     uint64
+//	DOUBLE   float64
+//	FLOAT    float64
+//	BYTES    string, or []byte for the binary collation & geometry, or json.RawMessage for JSON
+//	TIME     time.Duration
+//	DATETIME time.Time, in UTC
+//	SET      []string
+//	ENUM     string
+//	BIT      uint64
+//	DECIMAL  types.Decimal
+//
+// Slices returned alias b.
+func Field(b []byte, column *mysqlx_resultset.ColumnMetaData) (interface{}, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	switch column.GetType() {
+	case mysqlx_resultset.ColumnMetaData_SINT:
+		return Sint(b)
+	case mysqlx_resultset.ColumnMetaData_UINT:
+		return Uint(b)
+	case mysqlx_resultset.ColumnMetaData_DOUBLE:
+		return Double(b)
+	case mysqlx_resultset.ColumnMetaData_FLOAT:
+		f, err := Float(b)
+		return float64(f), err
+	case mysqlx_resultset.ColumnMetaData_BYTES:
+		v, err := Bytes(b)
+		if err != nil {
+			return nil, err
+		}
+		switch column.GetContentType() {
+		case uint32(mysqlx_resultset.ContentType_BYTES_GEOMETRY):
+			return v, nil
+		case uint32(mysqlx_resultset.ContentType_BYTES_JSON):
+			return json.RawMessage(v), nil
+		}
+		c := collation.Collation(column.GetCollation())
+		if c == 0 || c.IsBinary() {
+			if column.GetFlags()&flagRightPad != 0 && len(v) < int(column.GetLength()) {
+				v = append(v[:len(v):len(v)], make([]byte, int(column.GetLength())-len(v))...)
+			}
+			return v, nil
+		}
+		return string(v), nil
+	case mysqlx_resultset.ColumnMetaData_TIME:
+		return Time(b)
+	case mysqlx_resultset.ColumnMetaData_DATETIME:
+		return DateTime(b, time.UTC)
+	case mysqlx_resultset.ColumnMetaData_SET:
+		return Set(b)
+	case mysqlx_resultset.ColumnMetaData_ENUM:
+		v, err := Bytes(b)
+		if err != nil {
+			return nil, err
+		}
+		return string(v), nil
+	case mysqlx_resultset.ColumnMetaData_BIT:
+		return Bit(b)
+	case mysqlx_resultset.ColumnMetaData_DECIMAL:
+		return Decimal(b)
+	}
+	return nil, fmt.Errorf("unsupported column type %s", column.GetType())
+}
+
+func uvarint(b []byte) (uint64, []byte, error) {
+	x, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, b, ErrMalformed
+	}
+	return x, b[n:], nil
+}
+
+// Sint decodes a SINT field, a zigzag encoded varint.
+func Sint(b []byte) (int64, error) {
+	if len(b) == 0 {
+		return 0, ErrNull
+	}
+	x, b, err := uvarint(b)
+	if err != nil || len(b) > 0 {
+		return 0, ErrMalformed
+	}
+	return int64(x>>1) ^ -int64(x&1), nil
+}
+
+// Uint decodes an UINT field, a varint.
+func Uint(b []byte) (uint64, error) {
+	if len(b) == 0 {
+		return 0, ErrNull
+	}
+	x, b, err := uvarint(b)
+	if err != nil || len(b) > 0 {
+		return 0, ErrMalformed
+	}
+	return x, nil
+}
+
+// Bit decodes a BIT field, a varint.
+func Bit(b []byte) (uint64, error) {
+	return Uint(b)
+}
+
+// Double decodes a DOUBLE field, a little endian float64.
+func Double(b []byte) (float64, error) {
+	if len(b) == 0 {
+		return 0, ErrNull
+	}
+	if len(b) != 8 {
+		return 0, ErrMalformed
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+}
+
+// Float decodes a FLOAT field, a little endian float32.
+func Float(b []byte) (float32, error) {
+	if len(b) == 0 {
+		return 0, ErrNull
+	}
+	if len(b) != 4 {
+		return 0, ErrMalformed
+	}
+	return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+}
+
+// Bytes decodes a BYTES or ENUM field, removing the trailing 0x00 byte that distinguishes empty from NULL.
+// The returned slice aliases b.
+func Bytes(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, ErrNull
+	}
+	if b[len(b)-1] != 0 {
+		return nil, ErrMalformed
+	}
+	return b[: len(b)-1 : len(b)-1], nil
+}
+
+// String decodes a BYTES or ENUM field as a string.
+func String(b []byte) (string, error) {
+	v, err := Bytes(b)
+	return string(v), err
+}
+
+// Time decodes a TIME field, a sign byte followed by optional varints of hours, minutes, seconds & microseconds.
+func Time(b []byte) (time.Duration, error) {
+	if len(b) == 0 {
+		return 0, ErrNull
+	}
+	negate := b[0]
+	if negate > 1 {
+		return 0, ErrMalformed
+	}
+	var d time.Duration
+	b = b[1:]
+	for _, unit := range [...]time.Duration{time.Hour, time.Minute, time.Second, time.Microsecond} {
+		if len(b) == 0 {
+			break
+		}
+		x, bb, err := uvarint(b)
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(x) * unit
+		b = bb
+	}
+	if len(b) > 0 {
+		return 0, ErrMalformed
+	}
+	if negate == 1 {
+		d = -d
+	}
+	return d, nil
+}
+
+// DateTime decodes a DATETIME field, varints of year, month & day followed by optional varints of hours, minutes,
+// seconds & microseconds. The returned time is in the location loc.
+func DateTime(b []byte, loc *time.Location) (time.Time, error) {
+	var x [7]int
+
+	if len(b) == 0 {
+		return time.Time{}, ErrNull
+	}
+	i := 0
+	for ; i < len(x) && len(b) > 0; i++ {
+		v, bb, err := uvarint(b)
+		if err != nil {
+			return time.Time{}, err
+		}
+		x[i] = int(v)
+		b = bb
+	}
+	if i < 3 || len(b) > 0 {
+		return time.Time{}, ErrMalformed
+	}
+	return time.Date(x[0], time.Month(x[1]), x[2], x[3], x[4], x[5], x[6]*int(time.Microsecond), loc), nil
+}
+
+// Set decodes a SET field, a sequence of length prefixed strings.
+func Set(b []byte) ([]string, error) {
+	if len(b) == 0 {
+		return nil, ErrNull
+	}
+	if len(b) == 1 && b[0] == 0x01 {
+		return []string{}, nil
+	}
+	var s []string
+	for len(b) > 0 {
+		n, bb, err := uvarint(b)
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(bb)) {
+			return nil, ErrMalformed
+		}
+		s = append(s, string(bb[:n]))
+		b = bb[n:]
+	}
+	return s, nil
+}
+
+// Decimal decodes a DECIMAL field, a scale byte followed by packed BCD digits, and a sign nibble
+// of 0xC or 0xD.
+// The returned value aliases b.
+func Decimal(b []byte) (types.Decimal, error) {
+	if len(b) == 0 {
+		return nil, ErrNull
+	}
+	if len(b) < 2 {
+		return nil, ErrMalformed
+	}
+	// Digits must be followed by a sign nibble, 0xC or 0xD, in the last byte,
+	// padded with a zero nibble when in the high half.
+	last := len(b) - 1
+	for i, x := range b[1:] {
+		hi, lo := x>>4, x&0x0F
+		if hi > 9 {
+			if i+1 != last || !isDecimalSign(hi) || lo != 0 {
+				return nil, ErrMalformed
+			}
+			return types.Decimal(b), nil
+		}
+		if lo > 9 {
+			if i+1 != last || !isDecimalSign(lo) {
+				return nil, ErrMalformed
+			}
+			return types.Decimal(b), nil
+		}
+	}
+	return nil, ErrMalformed
+}
+
+// isDecimalSign reports whether the nibble x is a packed BCD sign, positive 0xC or negative 0xD.
+func isDecimalSign(x byte) bool {
+	return x == 0xC || x == 0xD
+}
@@ -0,0 +1,77 @@
+package xdecode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/renthraysk/xtorm/collation"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_datatypes"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_resultset"
+)
+
+// Scalar decodes a Scalar protobuf, as found in notices and capabilities, into a Go value.
+// V_NULL is returned as nil, V_SINT as int64, V_UINT as uint64, V_DOUBLE as float64, V_FLOAT as float32,
+// V_BOOL as bool, V_STRING as string, or []byte for the binary collation.
+// V_OCTETS is returned as []byte, or json.RawMessage for JSON content, or string for XML content.
+func Scalar(s *mysqlx_datatypes.Scalar) (interface{}, error) {
+	switch s.GetType() {
+	case mysqlx_datatypes.Scalar_V_NULL:
+		return nil, nil
+	case mysqlx_datatypes.Scalar_V_SINT:
+		return s.GetVSignedInt(), nil
+	case mysqlx_datatypes.Scalar_V_UINT:
+		return s.GetVUnsignedInt(), nil
+	case mysqlx_datatypes.Scalar_V_DOUBLE:
+		return s.GetVDouble(), nil
+	case mysqlx_datatypes.Scalar_V_FLOAT:
+		return s.GetVFloat(), nil
+	case mysqlx_datatypes.Scalar_V_BOOL:
+		return s.GetVBool(), nil
+	case mysqlx_datatypes.Scalar_V_STRING:
+		v := s.GetVString()
+		if collation.Collation(v.GetCollation()).IsBinary() {
+			return v.GetValue(), nil
+		}
+		return string(v.GetValue()), nil
+	case mysqlx_datatypes.Scalar_V_OCTETS:
+		v := s.GetVOctets()
+		switch v.GetContentType() {
+		case uint32(mysqlx_resultset.ContentType_BYTES_JSON):
+			return json.RawMessage(v.GetValue()), nil
+		case uint32(mysqlx_resultset.ContentType_BYTES_XML):
+			return string(v.GetValue()), nil
+		}
+		return v.GetValue(), nil
+	}
+	return nil, fmt.Errorf("unsupported scalar type %s", s.GetType())
+}
+
+// Any decodes an Any protobuf into a Go value.
+// SCALAR is decoded as per Scalar(), OBJECT as map[string]interface{}, and ARRAY as []interface{}.
+func Any(a *mysqlx_datatypes.Any) (interface{}, error) {
+	switch a.GetType() {
+	case mysqlx_datatypes.Any_SCALAR:
+		return Scalar(a.GetScalar())
+	case mysqlx_datatypes.Any_OBJECT:
+		m := make(map[string]interface{}, len(a.GetObj().GetFld()))
+		for _, f := range a.GetObj().GetFld() {
+			v, err := Any(f.GetValue())
+			if err != nil {
+				return nil, fmt.Errorf("object field %q: %w", f.GetKey(), err)
+			}
+			m[f.GetKey()] = v
+		}
+		return m, nil
+	case mysqlx_datatypes.Any_ARRAY:
+		s := make([]interface{}, 0, len(a.GetArray().GetValue()))
+		for i, e := range a.GetArray().GetValue() {
+			v, err := Any(e)
+			if err != nil {
+				return nil, fmt.Errorf("array element %d: %w", i, err)
+			}
+			s = append(s, v)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported any type %s", a.GetType())
+}
@@ -0,0 +1,206 @@
+package xdecode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/renthraysk/xtorm/collation"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_resultset"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_sql"
+	"github.com/renthraysk/xtorm/types"
+	"github.com/renthraysk/xtorm/xproto"
+)
+
+// Field encoders, the inverse of the decoders as the server would encode them.
+
+func appendFieldUint(p []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(p, b[:binary.PutUvarint(b[:], x)]...)
+}
+
+func appendFieldSint(p []byte, v int64) []byte {
+	return appendFieldUint(p, uint64(v)<<1^uint64(v>>63))
+}
+
+func appendFieldBytes(p []byte, b []byte) []byte {
+	return append(append(p, b...), 0)
+}
+
+func appendFieldTime(p []byte, d time.Duration) []byte {
+	if d < 0 {
+		p = append(p, 1)
+		d = -d
+	} else {
+		p = append(p, 0)
+	}
+	p = appendFieldUint(p, uint64(d/time.Hour))
+	p = appendFieldUint(p, uint64(d/time.Minute%60))
+	p = appendFieldUint(p, uint64(d/time.Second%60))
+	return appendFieldUint(p, uint64(d/time.Microsecond%1000000))
+}
+
+func appendFieldDateTime(p []byte, t time.Time) []byte {
+	p = appendFieldUint(p, uint64(t.Year()))
+	p = appendFieldUint(p, uint64(t.Month()))
+	p = appendFieldUint(p, uint64(t.Day()))
+	p = appendFieldUint(p, uint64(t.Hour()))
+	p = appendFieldUint(p, uint64(t.Minute()))
+	p = appendFieldUint(p, uint64(t.Second()))
+	return appendFieldUint(p, uint64(t.Nanosecond()/1000))
+}
+
+func appendFieldSet(p []byte, s []string) []byte {
+	if len(s) == 0 {
+		return append(p, 0x01)
+	}
+	for _, e := range s {
+		p = append(appendFieldUint(p, uint64(len(e))), e...)
+	}
+	return p
+}
+
+func column(t mysqlx_resultset.ColumnMetaData_FieldType) *mysqlx_resultset.ColumnMetaData {
+	return &mysqlx_resultset.ColumnMetaData{Type: &t}
+}
+
+func bytesColumn(c collation.Collation, contentType uint32) *mysqlx_resultset.ColumnMetaData {
+	cmd := column(mysqlx_resultset.ColumnMetaData_BYTES)
+	cmd.Collation = proto.Uint64(uint64(c))
+	if contentType != 0 {
+		cmd.ContentType = proto.Uint32(contentType)
+	}
+	return cmd
+}
+
+func TestField(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	tests := []struct {
+		column   *mysqlx_resultset.ColumnMetaData
+		field    []byte
+		expected interface{}
+	}{
+		{column(mysqlx_resultset.ColumnMetaData_SINT), nil, nil},
+		{column(mysqlx_resultset.ColumnMetaData_SINT), appendFieldSint(nil, 0), int64(0)},
+		{column(mysqlx_resultset.ColumnMetaData_SINT), appendFieldSint(nil, -1), int64(-1)},
+		{column(mysqlx_resultset.ColumnMetaData_SINT), appendFieldSint(nil, math.MinInt64), int64(math.MinInt64)},
+		{column(mysqlx_resultset.ColumnMetaData_SINT), appendFieldSint(nil, math.MaxInt64), int64(math.MaxInt64)},
+		{column(mysqlx_resultset.ColumnMetaData_UINT), appendFieldUint(nil, 0), uint64(0)},
+		{column(mysqlx_resultset.ColumnMetaData_UINT), appendFieldUint(nil, math.MaxUint64), uint64(math.MaxUint64)},
+		{column(mysqlx_resultset.ColumnMetaData_BIT), appendFieldUint(nil, 0x5), uint64(0x5)},
+		{column(mysqlx_resultset.ColumnMetaData_DOUBLE), []byte{0, 0, 0, 0, 0, 0, 0xF0, 0x3F}, float64(1)},
+		{column(mysqlx_resultset.ColumnMetaData_FLOAT), []byte{0, 0, 0x80, 0x3F}, float64(1)},
+		{bytesColumn(collation.UTF8mb40900AiCi, 0), appendFieldBytes(nil, nil), ""},
+		{bytesColumn(collation.UTF8mb40900AiCi, 0), appendFieldBytes(nil, []byte("abc")), "abc"},
+		{bytesColumn(collation.Binary, 0), appendFieldBytes(nil, []byte{0, 1, 2}), []byte{0, 1, 2}},
+		{bytesColumn(collation.UTF8mb4Bin, uint32(mysqlx_resultset.ContentType_BYTES_JSON)), appendFieldBytes(nil, []byte(`{"a":1}`)), json.RawMessage(`{"a":1}`)},
+		{bytesColumn(collation.Binary, uint32(mysqlx_resultset.ContentType_BYTES_GEOMETRY)), appendFieldBytes(nil, []byte{1, 2}), []byte{1, 2}},
+		{column(mysqlx_resultset.ColumnMetaData_ENUM), appendFieldBytes(nil, []byte("red")), "red"},
+		{column(mysqlx_resultset.ColumnMetaData_TIME), []byte{0}, time.Duration(0)},
+		{column(mysqlx_resultset.ColumnMetaData_TIME), appendFieldTime(nil, 42*time.Hour+time.Second+time.Microsecond), 42*time.Hour + time.Second + time.Microsecond},
+		{column(mysqlx_resultset.ColumnMetaData_TIME), appendFieldTime(nil, -42*time.Minute), -42 * time.Minute},
+		{column(mysqlx_resultset.ColumnMetaData_DATETIME), []byte{0xE4, 0x0F, 1, 2}, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{column(mysqlx_resultset.ColumnMetaData_DATETIME), appendFieldDateTime(nil, now), now},
+		{column(mysqlx_resultset.ColumnMetaData_SET), appendFieldSet(nil, nil), []string{}},
+		{column(mysqlx_resultset.ColumnMetaData_SET), []byte{0x00}, []string{""}},
+		{column(mysqlx_resultset.ColumnMetaData_SET), appendFieldSet(nil, []string{"FOO", "BAR"}), []string{"FOO", "BAR"}},
+		{column(mysqlx_resultset.ColumnMetaData_DECIMAL), []byte{0x04, 0x12, 0x34, 0x01, 0xD0}, types.Decimal{0x04, 0x12, 0x34, 0x01, 0xD0}},
+		{column(mysqlx_resultset.ColumnMetaData_DECIMAL), []byte{0x02, 0x12, 0x3C}, types.Decimal{0x02, 0x12, 0x3C}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v, err := Field(tt.field, tt.column)
+			if err != nil {
+				t.Fatalf("failed to decode field: %s", err)
+			}
+			if !reflect.DeepEqual(v, tt.expected) {
+				t.Fatalf("expected %T(%v), got %T(%v)", tt.expected, tt.expected, v, v)
+			}
+		})
+	}
+}
+
+func TestFieldMalformed(t *testing.T) {
+	tests := []struct {
+		column *mysqlx_resultset.ColumnMetaData
+		field  []byte
+	}{
+		{column(mysqlx_resultset.ColumnMetaData_SINT), []byte{0x80}},
+		{column(mysqlx_resultset.ColumnMetaData_UINT), []byte{0x01, 0x01}},
+		{column(mysqlx_resultset.ColumnMetaData_DOUBLE), []byte{0, 0, 0x80, 0x3F}},
+		{column(mysqlx_resultset.ColumnMetaData_FLOAT), []byte{0, 0, 0, 0, 0, 0, 0xF0, 0x3F}},
+		{bytesColumn(collation.UTF8mb40900AiCi, 0), []byte("abc")},
+		{column(mysqlx_resultset.ColumnMetaData_TIME), []byte{2}},
+		{column(mysqlx_resultset.ColumnMetaData_DATETIME), []byte{0xE4, 0x0F, 1}},
+		{column(mysqlx_resultset.ColumnMetaData_SET), []byte{0x03, 'F'}},
+		{column(mysqlx_resultset.ColumnMetaData_DECIMAL), []byte{0x04, 0x12, 0x34}},
+		{column(mysqlx_resultset.ColumnMetaData_DECIMAL), []byte{0x04, 0x12, 0x34, 0x01, 0xE0}},
+		{column(mysqlx_resultset.ColumnMetaData_DECIMAL), []byte{0x04, 0x12, 0x34, 0x01, 0xDC}},
+		{column(mysqlx_resultset.ColumnMetaData_DECIMAL), []byte{0x04, 0x12, 0x3C, 0x01, 0xD0}},
+		{column(mysqlx_resultset.ColumnMetaData_DECIMAL), []byte{0x04, 0x12, 0x34, 0x0A, 0xD0}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if _, err := Field(tt.field, tt.column); err == nil {
+				t.Fatalf("expected error decoding %x", tt.field)
+			}
+		})
+	}
+}
+
+func TestScalarRoundTrip(t *testing.T) {
+	tests := []struct {
+		arg      interface{}
+		expected interface{}
+	}{
+		{nil, nil},
+		{true, true},
+		{int8(-1), int64(-1)},
+		{int64(math.MinInt64), int64(math.MinInt64)},
+		{uint16(math.MaxUint16), uint64(math.MaxUint16)},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64)},
+		{float32(1.5), float32(1.5)},
+		{math.MaxFloat64, math.MaxFloat64},
+		{"abcdef", "abcdef"},
+		{[]byte{0, 1, 2}, []byte{0, 1, 2}},
+		{42 * time.Second, []byte("0:00:42")},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%T(%v)", tt.arg, tt.arg), func(t *testing.T) {
+			var s mysqlx_sql.StmtExecute
+
+			b, err := xproto.StmtExecute(nil, "SELECT ?", []interface{}{tt.arg})
+			if err != nil {
+				t.Fatalf("failed to marshal stmtexecute: %s", err)
+			}
+			if err := proto.Unmarshal(b[5:], &s); err != nil {
+				t.Fatalf("failed to unmarshal stmtexecute: %s", err)
+			}
+			v, err := Any(s.GetArgs()[0])
+			if err != nil {
+				t.Fatalf("failed to decode any: %s", err)
+			}
+			if bb, ok := tt.expected.([]byte); ok {
+				if !bytes.Equal(v.([]byte), bb) {
+					t.Fatalf("expected %x, got %x", bb, v)
+				}
+				return
+			}
+			if !reflect.DeepEqual(v, tt.expected) {
+				t.Fatalf("expected %T(%v), got %T(%v)", tt.expected, tt.expected, v, v)
+			}
+		})
+	}
+}
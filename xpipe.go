@@ -15,9 +15,12 @@ func New(n int) *XPipe {
 	return &XPipe{Builder{buf: make([]byte, 0, n)}}
 }
 
+// Send sends the unit of work, returning the responses to each message. If the server responded to any message with
+// an error, an *OpError is returned for the first, identifying the operation that caused it.
 func (x *XPipe) Send(ctx context.Context, s netx.Sender) ([]netx.Response, error) {
 	//	x.xp.Reset(&x.buffer, true) // @TODO MySQL 8.0.16+ specific
-	return x.send(ctx, s)
+	r, err := x.send(ctx, s)
+	return r, x.opError(r, err)
 }
 
 func (x *XPipe) Reset() {
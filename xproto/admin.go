@@ -0,0 +1,22 @@
+package xproto
+
+import (
+	"encoding/binary"
+)
+
+// AdminCommand appends a StmtExecute of the X Plugin admin command cmd, in the mysqlx namespace, with optional args.
+func AdminCommand(p []byte, cmd string, args Object) ([]byte, error) {
+	var a []interface{}
+
+	if args != nil {
+		a = []interface{}{args}
+	}
+	n := len(p)
+	p, err := StmtExecute(p, cmd, a)
+	if err != nil {
+		return p, err
+	}
+	p = appendWireString(p, tagStmtExecuteNamespace, "mysqlx")
+	binary.LittleEndian.PutUint32(p[n:], uint32(len(p)-n-4))
+	return p, nil
+}
@@ -389,6 +389,10 @@ typeSwitch:
 		return appendAnyTime(p, tag, v), nil
 	case time.Duration:
 		return appendAnyDuration(p, tag, v), nil
+	case map[string]interface{}:
+		return appendAnyMap(p, tag, reflect.ValueOf(v))
+	case []interface{}:
+		return Array(v).AppendAny(p, tag)
 
 	default:
 		if ae, ok := v.(AppendAny); ok {
@@ -4,45 +4,53 @@ import (
 	"encoding/binary"
 	"fmt"
 
-	"github.com/golang/protobuf/proto"
-
 	"github.com/renthraysk/xtorm/protobuf/mysqlx"
-	"github.com/renthraysk/xtorm/protobuf/mysqlx_connection"
-	"github.com/renthraysk/xtorm/protobuf/mysqlx_datatypes"
 )
 
-func anyType(x mysqlx_datatypes.Any_Type) *mysqlx_datatypes.Any_Type {
-	return (*mysqlx_datatypes.Any_Type)(toPointer32(int32(x)))
+const (
+	// Tag from CapabilitiesSet protobuf
+	tagCapabilitiesSetCapabilities = 1
+
+	// Tag from Capabilities protobuf
+	tagCapabilitiesCapabilities = 1
+
+	// Tags from Capability protobuf
+	tagCapabilityName  = 1
+	tagCapabilityValue = 2
+)
+
+// CapabilitiesGet appends a CapabilitiesGet message, requesting the capabilities of the server.
+func CapabilitiesGet(p []byte) []byte {
+	return append(p[len(p):], 1, 0, 0, 0, byte(mysqlx.ClientMessages_CON_CAPABILITIES_GET))
 }
 
-func scalarType(x mysqlx_datatypes.Scalar_Type) *mysqlx_datatypes.Scalar_Type {
-	return (*mysqlx_datatypes.Scalar_Type)(toPointer32(int32(x)))
+// CapabilitySet appends a CapabilitiesSet message, setting the capability name to value, which may be structured,
+// eg an Object.
+func CapabilitySet(p []byte, name string, value interface{}) ([]byte, error) {
+	return CapabilitiesSet(p, Object{{Key: name, Value: value}})
 }
 
-func CapabilitySet(name string, enable bool) ([]byte, error) {
-	cs := mysqlx_connection.CapabilitiesSet{
-		Capabilities: &mysqlx_connection.Capabilities{
-			Capabilities: []*mysqlx_connection.Capability{
-				{
-					Name: &name,
-					Value: &mysqlx_datatypes.Any{
-						Type:   anyType(mysqlx_datatypes.Any_SCALAR),
-						Scalar: &mysqlx_datatypes.Scalar{Type: scalarType(mysqlx_datatypes.Scalar_V_BOOL), VBool: &enable},
-					},
-				},
-			},
-		},
-	}
+// CapabilitiesSet appends a CapabilitiesSet message, setting each of the capabilities, keyed by name.
+func CapabilitiesSet(p []byte, capabilities Object) ([]byte, error) {
+	p = append(p[len(p):], 0, 0, 0, 0, byte(mysqlx.ClientMessages_CON_CAPABILITIES_SET))
+	for _, c := range capabilities {
+		var err error
 
-	n := proto.Size(&cs)
-	b := make([]byte, 5+n)
-	binary.LittleEndian.PutUint32(b, uint32(n+1))
-	b[4] = byte(mysqlx.ClientMessages_CON_CAPABILITIES_SET)
-	buf := proto.NewBuffer(b[:5])
-	if err := buf.Marshal(&cs); err != nil {
-		return nil, fmt.Errorf("failed to marshal CapabilitiesSet: %w", err)
+		i := len(p)
+		p = appendWireString(p, tagCapabilityName, c.Key)
+		if p, err = appendAny(p, tagCapabilityValue, c.Value); err != nil {
+			return p, fmt.Errorf("capability %s: %w", c.Key, err)
+		}
+		p = insertMessageHeader(p, i, tagCapabilitiesCapabilities)
 	}
-	return b, nil
+	p = insertMessageHeader(p, 5, tagCapabilitiesSetCapabilities)
+	binary.LittleEndian.PutUint32(p, uint32(len(p)-4))
+	return p, nil
+}
+
+// ConnectionClose appends a Connection Close message, requesting the server close the session and connection.
+func ConnectionClose(p []byte) []byte {
+	return append(p[len(p):], 1, 0, 0, 0, byte(mysqlx.ClientMessages_CON_CLOSE))
 }
 
 func AuthenticateStart(p []byte, mechName string, authData []byte) []byte {
@@ -0,0 +1,24 @@
+package xproto
+
+import (
+	"encoding/binary"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+)
+
+const (
+	// Tags from Compression protobuf
+	tagCompressionUncompressedSize = 1
+	tagCompressionPayload          = 4
+)
+
+// Compression appends header and mysqlx_connection.Compression protobuf, of payload, the compressed form of
+// uncompressedSize bytes of complete messages.
+func Compression(p []byte, uncompressedSize uint64, payload []byte) []byte {
+	n := len(p)
+	p = append(p, 0, 0, 0, 0, byte(mysqlx.ClientMessages_COMPRESSION))
+	p = appendWireUvarint(p, tagCompressionUncompressedSize, uncompressedSize)
+	p = appendWireBytes(p, tagCompressionPayload, payload)
+	binary.LittleEndian.PutUint32(p[n:], uint32(len(p)-n-4))
+	return p
+}
@@ -0,0 +1,74 @@
+package xproto
+
+import (
+	"encoding/binary"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_cursor"
+)
+
+const (
+	tagCursorOpenCursorId  = 1
+	tagCursorOpenStmt      = 4
+	tagCursorOpenFetchRows = 5
+
+	tagCursorOpenOneOfType           = 1
+	tagCursorOpenOneOfPrepareExecute = 2
+
+	tagCursorFetchCursorId  = 1
+	tagCursorFetchFetchRows = 5
+
+	tagCursorCloseCursorId = 1
+)
+
+// CursorOpen appends header and mysqlx_cursor.Open protobuf, to open cursor with cursorID over the execution of prepared
+// statement stmtID with args. The first fetchRows rows are returned in response, or all rows if fetchRows is 0.
+func CursorOpen(p []byte, cursorID, stmtID uint32, args []interface{}, fetchRows uint64) ([]byte, error) {
+	const (
+		tagExecuteStmtId = 1
+		tagExecuteArgs   = 2
+	)
+	n := len(p)
+	p = append(p, 0, 0, 0, 0, byte(mysqlx.ClientMessages_CURSOR_OPEN))
+	p = appendWireUvarint(p, tagCursorOpenCursorId, uint64(cursorID))
+	i := len(p)
+	p = append(p, tagCursorOpenOneOfType<<3|wireVarint, byte(mysqlx_cursor.Open_OneOfMessage_PREPARE_EXECUTE))
+	j := len(p)
+	p = appendWireUvarint(p, tagExecuteStmtId, uint64(stmtID))
+	for _, arg := range args {
+		var err error
+		p, err = appendAny(p, tagExecuteArgs, arg)
+		if err != nil {
+			return p, err
+		}
+	}
+	p = insertMessageHeader(p, j, tagCursorOpenOneOfPrepareExecute)
+	p = insertMessageHeader(p, i, tagCursorOpenStmt)
+	if fetchRows > 0 {
+		p = appendWireUvarint(p, tagCursorOpenFetchRows, fetchRows)
+	}
+	binary.LittleEndian.PutUint32(p[n:], uint32(len(p)-n-4))
+	return p, nil
+}
+
+// CursorFetch appends header and mysqlx_cursor.Fetch protobuf, to fetch the next fetchRows rows from cursor with
+// cursorID, or all remaining rows if fetchRows is 0.
+func CursorFetch(p []byte, cursorID uint32, fetchRows uint64) []byte {
+	n := len(p)
+	p = append(p, 0, 0, 0, 0, byte(mysqlx.ClientMessages_CURSOR_FETCH))
+	p = appendWireUvarint(p, tagCursorFetchCursorId, uint64(cursorID))
+	if fetchRows > 0 {
+		p = appendWireUvarint(p, tagCursorFetchFetchRows, fetchRows)
+	}
+	binary.LittleEndian.PutUint32(p[n:], uint32(len(p)-n-4))
+	return p
+}
+
+// CursorClose appends header and mysqlx_cursor.Close protobuf, to close cursor with cursorID.
+func CursorClose(p []byte, cursorID uint32) []byte {
+	n := len(p)
+	p = append(p, 0, 0, 0, 0, byte(mysqlx.ClientMessages_CURSOR_CLOSE))
+	p = appendWireUvarint(p, tagCursorCloseCursorId, uint64(cursorID))
+	binary.LittleEndian.PutUint32(p[n:], uint32(len(p)-n-4))
+	return p
+}
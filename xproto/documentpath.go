@@ -0,0 +1,62 @@
+package xproto
+
+import (
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_expr"
+)
+
+const (
+	// Tags from DocumentPathItem protobuf
+	tagDocumentPathItemType  = 1
+	tagDocumentPathItemValue = 2
+	tagDocumentPathItemIndex = 3
+)
+
+type DocumentPathItemType uint8
+
+const (
+	DocumentPathMember             = DocumentPathItemType(mysqlx_expr.DocumentPathItem_MEMBER)
+	DocumentPathMemberAsterisk     = DocumentPathItemType(mysqlx_expr.DocumentPathItem_MEMBER_ASTERISK)
+	DocumentPathArrayIndex         = DocumentPathItemType(mysqlx_expr.DocumentPathItem_ARRAY_INDEX)
+	DocumentPathArrayIndexAsterisk = DocumentPathItemType(mysqlx_expr.DocumentPathItem_ARRAY_INDEX_ASTERISK)
+	DocumentPathDoubleAsterisk     = DocumentPathItemType(mysqlx_expr.DocumentPathItem_DOUBLE_ASTERISK)
+)
+
+// DocumentPathItem is a step in a path into a JSON document, a member Value of an object, or an Index into an array.
+type DocumentPathItem struct {
+	Type  DocumentPathItemType
+	Value string
+	Index uint32
+}
+
+// DocumentPath is a path into a JSON document, eg $.a.b[0]
+type DocumentPath []DocumentPathItem
+
+func appendDocumentPath(p []byte, tag uint8, path DocumentPath) []byte {
+	for _, item := range path {
+		i := len(p)
+		p = append(p, tagDocumentPathItemType<<3|wireVarint, byte(item.Type))
+		switch item.Type {
+		case DocumentPathMember:
+			p = appendWireString(p, tagDocumentPathItemValue, item.Value)
+		case DocumentPathArrayIndex:
+			p = appendWireUvarint(p, tagDocumentPathItemIndex, uint64(item.Index))
+		}
+		p = insertMessageHeader(p, i, tag)
+	}
+	return p
+}
+
+// AppendExpr appends an identifier expression of the document path, for referring to fields of documents in criteria.
+func (d DocumentPath) AppendExpr(p []byte, tag uint8) ([]byte, error) {
+	return AppendExprDocumentPath(p, tag, d)
+}
+
+// AppendExprDocumentPath appends an identifier expression of the path within a document.
+func AppendExprDocumentPath(p []byte, tag uint8, path DocumentPath) ([]byte, error) {
+	i := len(p)
+	p = append(p, tagExprType<<3|wireVarint, byte(mysqlx_expr.Expr_IDENT))
+	j := len(p)
+	p = appendDocumentPath(p, tagColumnIdentifierDocumentPath, path)
+	p = insertMessageHeader(p, j, tagExprIdentifier)
+	return insertMessageHeader(p, i, tag), nil
+}
@@ -391,8 +391,19 @@ func AppendExprFunctionCall(p []byte, tag uint8, name string, params []interface
 }
 
 func AppendExprColumn(p []byte, tag uint8, name string) ([]byte, error) {
+	return AppendExprQualifiedColumn(p, tag, "", "", name)
+}
+
+// AppendExprQualifiedColumn appends an identifier expression of column name, qualified by table and schema if not empty.
+func AppendExprQualifiedColumn(p []byte, tag uint8, schema, table, name string) ([]byte, error) {
 	n := len(name)
 	n0 := 1 + sizeVarint(uint(n)) + n
+	if table != "" {
+		n0 += 1 + sizeVarint(uint(len(table))) + len(table)
+	}
+	if schema != "" {
+		n0 += 1 + sizeVarint(uint(len(schema))) + len(schema)
+	}
 	n1 := 3 + sizeVarint(uint(n0)) + n0
 	p, b := slice.ForAppend(p, 1+sizeVarint(uint(n1))+n1)
 
@@ -408,7 +419,19 @@ func AppendExprColumn(p []byte, tag uint8, name string) ([]byte, error) {
 	b[i] = tagColumnIdentifierName<<3 | wireBytes
 	i++
 	i += putUvarint(b[i:], uint64(n))
-	copy(b[i:], name)
+	i += copy(b[i:], name)
+	if table != "" {
+		b[i] = tagColumnIndetifierTable<<3 | wireBytes
+		i++
+		i += putUvarint(b[i:], uint64(len(table)))
+		i += copy(b[i:], table)
+	}
+	if schema != "" {
+		b[i] = tagColumnIdentifierSchema<<3 | wireBytes
+		i++
+		i += putUvarint(b[i:], uint64(len(schema)))
+		copy(b[i:], schema)
+	}
 	return p, nil
 }
 
@@ -0,0 +1,183 @@
+package xproto
+
+import (
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_crud"
+	"github.com/renthraysk/xtorm/slice"
+)
+
+const (
+	tagFindCollection       = 2
+	tagFindDataModel        = 3
+	tagFindProjection       = 4
+	tagFindCriteria         = 5
+	tagFindLimit            = 6
+	tagFindOrder            = 7
+	tagFindGrouping         = 8
+	tagFindGroupingCriteria = 9
+	tagFindArgs             = 11
+	tagFindLocking          = 12
+	tagFindLockingOptions   = 13
+	tagFindLimitExpr        = 14
+
+	// Tags from Projection protobuf
+	tagProjectionSource = 1
+	tagProjectionAlias  = 2
+
+	// Tags from Order protobuf
+	tagOrderExpr      = 1
+	tagOrderDirection = 2
+
+	// Tags from Limit protobuf
+	tagLimitRowCount = 1
+	tagLimitOffset   = 2
+
+	// Tags from LimitExpr protobuf
+	tagLimitExprRowCount = 1
+	tagLimitExprOffset   = 2
+)
+
+// Find appends the header and start of a mysqlx_crud.Find protobuf, for finding rows in table name, in schema or the
+// default schema if empty, that match criteria.
+// Length in the header is left for the caller to fill in, after appending projections, order etc.
+func Find(p []byte, schema, name string, criteria AppendExprFunc) ([]byte, error) {
+	return find(p, schema, name, mysqlx_crud.DataModel_TABLE, criteria)
+}
+
+// FindDocuments appends the header and start of a mysqlx_crud.Find protobuf, for finding documents in the collection
+// name, in schema or the default schema if empty, that match criteria.
+// Length in the header is left for the caller to fill in, after appending projections, order etc.
+func FindDocuments(p []byte, schema, name string, criteria AppendExprFunc) ([]byte, error) {
+	return find(p, schema, name, mysqlx_crud.DataModel_DOCUMENT, criteria)
+}
+
+func find(p []byte, schema, name string, model mysqlx_crud.DataModel, criteria AppendExprFunc) ([]byte, error) {
+	n1 := sizeCollection(schema, name)
+	p, b := slice.ForAppend(p, 4+1+3+sizeVarint(uint(n1))+n1)
+	i := 6 + putUvarint(b[6:], uint64(n1))
+	b[4] = byte(mysqlx.ClientMessages_CRUD_FIND)
+	b[5] = tagFindCollection<<3 | wireBytes
+	i += putCollection(b[i:], schema, name)
+	b[i] = tagFindDataModel<<3 | wireVarint
+	i++
+	b[i] = byte(model)
+	if criteria != nil {
+		return criteria(p, tagFindCriteria)
+	}
+	return p, nil
+}
+
+// AppendFindProjection appends a Projection of the expression source, with an optional alias.
+func AppendFindProjection(p []byte, source interface{}, alias string) ([]byte, error) {
+	i := len(p)
+	p, err := appendExpr(p, tagProjectionSource, source)
+	if err != nil {
+		return p, err
+	}
+	if alias != "" {
+		p = appendWireString(p, tagProjectionAlias, alias)
+	}
+	return insertMessageHeader(p, i, tagFindProjection), nil
+}
+
+// AppendFindOrder appends an Order of the expression expr, descending if desc is true.
+func AppendFindOrder(p []byte, expr interface{}, desc bool) ([]byte, error) {
+	return appendOrder(p, tagFindOrder, expr, desc)
+}
+
+// AppendFindGrouping appends the expression expr to the grouping (GROUP BY) list.
+func AppendFindGrouping(p []byte, expr interface{}) ([]byte, error) {
+	return appendExpr(p, tagFindGrouping, expr)
+}
+
+// AppendFindGroupingCriteria appends criteria for filtering aggregated groups (HAVING).
+func AppendFindGroupingCriteria(p []byte, criteria AppendExprFunc) ([]byte, error) {
+	return criteria(p, tagFindGroupingCriteria)
+}
+
+// AppendFindLimit appends a Limit, of at most rowCount rows after skipping offset rows.
+func AppendFindLimit(p []byte, rowCount, offset uint64) []byte {
+	return appendLimit(p, tagFindLimit, rowCount, offset)
+}
+
+// AppendFindLimitExpr appends a LimitExpr, of at most the expression rowCount rows after skipping the expression
+// offset rows, typically placeholders. A nil offset skips no rows.
+func AppendFindLimitExpr(p []byte, rowCount, offset interface{}) ([]byte, error) {
+	return appendLimitExpr(p, tagFindLimitExpr, rowCount, offset)
+}
+
+func appendOrder(p []byte, tag uint8, expr interface{}, desc bool) ([]byte, error) {
+	i := len(p)
+	p, err := appendExpr(p, tagOrderExpr, expr)
+	if err != nil {
+		return p, err
+	}
+	if desc {
+		p = append(p, tagOrderDirection<<3|wireVarint, byte(mysqlx_crud.Order_DESC))
+	}
+	return insertMessageHeader(p, i, tag), nil
+}
+
+func appendLimit(p []byte, tag uint8, rowCount, offset uint64) []byte {
+	n := 1 + sizeVarint64(rowCount)
+	if offset > 0 {
+		n += 1 + sizeVarint64(offset)
+	}
+	p, b := slice.ForAppend(p, 2+n)
+	b[0] = tag<<3 | wireBytes
+	b[1] = byte(n)
+	b[2] = tagLimitRowCount<<3 | wireVarint
+	i := 3 + putUvarint(b[3:], rowCount)
+	if offset > 0 {
+		b[i] = tagLimitOffset<<3 | wireVarint
+		putUvarint(b[i+1:], offset)
+	}
+	return p
+}
+
+func appendLimitExpr(p []byte, tag uint8, rowCount, offset interface{}) ([]byte, error) {
+	i := len(p)
+	p, err := appendExpr(p, tagLimitExprRowCount, rowCount)
+	if err != nil {
+		return p, err
+	}
+	if offset != nil {
+		if p, err = appendExpr(p, tagLimitExprOffset, offset); err != nil {
+			return p, err
+		}
+	}
+	return insertMessageHeader(p, i, tag), nil
+}
+
+// insertMessageHeader inserts the tag and length of an embedded message, which has been appended from position i.
+func insertMessageHeader(p []byte, i int, tag uint8) []byte {
+	n := len(p) - i
+	p = slice.Insert(p, i, 1+sizeVarint(uint(n)))
+	p[i] = tag<<3 | wireBytes
+	putUvarint(p[i+1:], uint64(n))
+	return p
+}
+
+type RowLock uint8
+
+const (
+	RowLockShared    = RowLock(mysqlx_crud.Find_SHARED_LOCK)
+	RowLockExclusive = RowLock(mysqlx_crud.Find_EXCLUSIVE_LOCK)
+)
+
+type RowLockOptions uint8
+
+const (
+	RowLockWait       RowLockOptions = 0
+	RowLockNoWait                    = RowLockOptions(mysqlx_crud.Find_NOWAIT)
+	RowLockSkipLocked                = RowLockOptions(mysqlx_crud.Find_SKIP_LOCKED)
+)
+
+// AppendFindLocking appends the row locking mode, with options for handling rows already locked.
+func AppendFindLocking(p []byte, lock RowLock, options RowLockOptions) []byte {
+	p = append(p, tagFindLocking<<3|wireVarint, byte(lock))
+	if options != RowLockWait {
+		p = append(p, tagFindLockingOptions<<3|wireVarint, byte(options))
+	}
+	return p
+}
@@ -24,22 +24,44 @@ const (
 	tagTypedRowField = 1
 )
 
-func Insert(p []byte, tableName string, names []string) []byte {
-	var b []byte
+// sizeCollection returns the size of a Collection protobuf, of tableName qualified by schema if not empty.
+func sizeCollection(schema, tableName string) int {
+	n := 1 + sizeVarint(uint(len(tableName))) + len(tableName)
+	if schema != "" {
+		n += 1 + sizeVarint(uint(len(schema))) + len(schema)
+	}
+	return n
+}
 
-	n := len(tableName)
-	n0 := 1 + sizeVarint(uint(n)) + n
-	p, b = slice.ForAppend(p, 5+3+sizeVarint(uint(n0))+n0)
-	b[4] = byte(mysqlx.ClientMessages_CRUD_INSERT)
-	b[5] = tagInsertCollection<<3 | wireBytes
-	i := 6 + putUvarint(b[6:], uint64(n0))
-	b[i] = tagCollectionName<<3 | wireBytes
-	i++
-	i += putUvarint(b[i:], uint64(n))
+// putCollection writes a Collection protobuf into b, returning the number of bytes written.
+func putCollection(b []byte, schema, tableName string) int {
+	b[0] = tagCollectionName<<3 | wireBytes
+	i := 1 + putUvarint(b[1:], uint64(len(tableName)))
 	i += copy(b[i:], tableName)
-	b[i] = tagInsertDataModel<<3 | wireVarint
-	i++
-	b[i] = byte(mysqlx_crud.DataModel_TABLE)
+	if schema != "" {
+		b[i] = tagCollectionSchema<<3 | wireBytes
+		i++
+		i += putUvarint(b[i:], uint64(len(schema)))
+		i += copy(b[i:], schema)
+	}
+	return i
+}
+
+// appendCollection appends a Collection protobuf with tag.
+func appendCollection(p []byte, tag uint8, schema, tableName string) []byte {
+	n := sizeCollection(schema, tableName)
+	p, b := slice.ForAppend(p, 1+sizeVarint(uint(n))+n)
+	b[0] = tag<<3 | wireBytes
+	i := 1 + putUvarint(b[1:], uint64(n))
+	putCollection(b[i:], schema, tableName)
+	return p
+}
+
+// Insert appends the header and start of a mysqlx_crud.Insert protobuf, for inserting into table tableName in schema,
+// or the default schema if empty, the columns names.
+// Length in the header is left for the caller to fill in, after appending rows.
+func Insert(p []byte, schema, tableName string, names []string) []byte {
+	p = insert(p, schema, tableName, mysqlx_crud.DataModel_TABLE)
 	for _, name := range names {
 		var b []byte
 		n := len(name)
@@ -56,6 +78,32 @@ func Insert(p []byte, tableName string, names []string) []byte {
 	return p
 }
 
+// InsertDocuments appends the header and start of a mysqlx_crud.Insert protobuf, for inserting documents into the
+// collection name in schema, or the default schema if empty. Each document is appended as a single field row.
+// Length in the header is left for the caller to fill in, after appending rows.
+func InsertDocuments(p []byte, schema, name string) []byte {
+	return insert(p, schema, name, mysqlx_crud.DataModel_DOCUMENT)
+}
+
+func insert(p []byte, schema, name string, model mysqlx_crud.DataModel) []byte {
+	n0 := sizeCollection(schema, name)
+	p, b := slice.ForAppend(p, 5+3+sizeVarint(uint(n0))+n0)
+	b[4] = byte(mysqlx.ClientMessages_CRUD_INSERT)
+	b[5] = tagInsertCollection<<3 | wireBytes
+	i := 6 + putUvarint(b[6:], uint64(n0))
+	i += putCollection(b[i:], schema, name)
+	b[i] = tagInsertDataModel<<3 | wireVarint
+	i++
+	b[i] = byte(model)
+	return p
+}
+
+// AppendInsertUpsert appends the upsert flag, so inserted documents replace existing documents with the same _id.
+// Only supported by the server for the DOCUMENT data model.
+func AppendInsertUpsert(p []byte) []byte {
+	return append(p, tagInsertUpsert<<3|wireVarint, 1)
+}
+
 func AppendInsertRow(p []byte, row []interface{}) ([]byte, error) {
 	const rowSizeSize = 2
 
@@ -0,0 +1,122 @@
+package xproto
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_datatypes"
+)
+
+const (
+	// Tag from Object protobuf
+	tagObjectField = 1
+
+	// Tags from ObjectField protobuf
+	tagObjectFieldKey   = 1
+	tagObjectFieldValue = 2
+
+	// Tag from Array protobuf
+	tagArrayValue = 1
+)
+
+// ObjectField is a key and value pair of an Object.
+type ObjectField struct {
+	Key   string
+	Value interface{}
+}
+
+// Object is an ordered list of fields, passed as an Any of type OBJECT, eg the arguments of admin commands.
+type Object []ObjectField
+
+func (o Object) AppendAny(p []byte, tag uint8) ([]byte, error) {
+	i := len(p)
+	p = append(p, tagAnyType<<3|wireVarint, byte(mysqlx_datatypes.Any_OBJECT))
+	j := len(p)
+	for _, f := range o {
+		var err error
+
+		k := len(p)
+		p = appendWireString(p, tagObjectFieldKey, f.Key)
+		if p, err = appendAny(p, tagObjectFieldValue, f.Value); err != nil {
+			return p, err
+		}
+		p = insertMessageHeader(p, k, tagObjectField)
+	}
+	p = insertMessageHeader(p, j, tagAnyObject)
+	return insertMessageHeader(p, i, tag), nil
+}
+
+// Array is a list of values, passed as an Any of type ARRAY.
+type Array []interface{}
+
+func (a Array) AppendAny(p []byte, tag uint8) ([]byte, error) {
+	i := len(p)
+	p = append(p, tagAnyType<<3|wireVarint, byte(mysqlx_datatypes.Any_ARRAY))
+	j := len(p)
+	for _, v := range a {
+		var err error
+
+		if p, err = appendAny(p, tagArrayValue, v); err != nil {
+			return p, err
+		}
+	}
+	p = insertMessageHeader(p, j, tagAnyArray)
+	return insertMessageHeader(p, i, tag), nil
+}
+
+// appendAnyMap appends an Any of type OBJECT, of the string keyed map m, with fields sorted by key.
+func appendAnyMap(p []byte, tag uint8, m reflect.Value) ([]byte, error) {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	o := make(Object, len(keys))
+	for i, k := range keys {
+		o[i] = ObjectField{Key: k.String(), Value: m.MapIndex(k).Interface()}
+	}
+	return o.AppendAny(p, tag)
+}
+
+// appendAnySlice appends an Any of type ARRAY, of the elements of the slice or array s.
+func appendAnySlice(p []byte, tag uint8, s reflect.Value) ([]byte, error) {
+	a := make(Array, s.Len())
+	for i := range a {
+		a[i] = s.Index(i).Interface()
+	}
+	return a.AppendAny(p, tag)
+}
+
+type anyObject struct {
+	v interface{}
+}
+
+// AnyObject opts v, a map with string keys, into encoding as an Any of type OBJECT.
+// Only map[string]interface{} is encoded as an OBJECT without opting in.
+func AnyObject(v interface{}) AppendAny {
+	return anyObject{v: v}
+}
+
+func (o anyObject) AppendAny(p []byte, tag uint8) ([]byte, error) {
+	rv := reflect.ValueOf(o.v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return p, fmt.Errorf("unsupported object type %T, expected a map with string keys", o.v)
+	}
+	return appendAnyMap(p, tag, rv)
+}
+
+type anyArray struct {
+	v interface{}
+}
+
+// AnyArray opts v, a slice or array, into encoding as an Any of type ARRAY.
+// Only []interface{} is encoded as an ARRAY without opting in, as []byte is encoded as bytes.
+func AnyArray(v interface{}) AppendAny {
+	return anyArray{v: v}
+}
+
+func (a anyArray) AppendAny(p []byte, tag uint8) ([]byte, error) {
+	rv := reflect.ValueOf(a.v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return p, fmt.Errorf("unsupported array type %T, expected a slice or array", a.v)
+	}
+	return appendAnySlice(p, tag, rv)
+}
@@ -2,6 +2,8 @@ package xproto
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 
 	"github.com/renthraysk/xtorm/protobuf/mysqlx"
 	"github.com/renthraysk/xtorm/protobuf/mysqlx_prepare"
@@ -42,6 +44,60 @@ func Prepare(p []byte, id uint32, stmt string) []byte {
 	return p
 }
 
+// PrepareCrud converts the Find, Insert, Update or Delete message appended at p[i:] into a Prepare message, preparing
+// it with id. Values to be supplied on Execute are expressed as placeholders in the message.
+func PrepareCrud(p []byte, i int, id uint32) ([]byte, error) {
+	const (
+		tagPrepareStmtId = 1
+		tagPrepareStmt   = 2
+
+		tagPrepareOneOfType   = 1
+		tagPrepareOneOfFind   = 2
+		tagPrepareOneOfInsert = 3
+		tagPrepareOneOfUpdate = 4
+		tagPrepareOneOfDelete = 5
+	)
+	var typ mysqlx_prepare.Prepare_OneOfMessage_Type
+	var tag uint8
+
+	if len(p) < i+5 {
+		return p, errors.New("no message to prepare")
+	}
+	switch mysqlx.ClientMessages_Type(p[i+4]) {
+	case mysqlx.ClientMessages_CRUD_FIND:
+		typ, tag = mysqlx_prepare.Prepare_OneOfMessage_FIND, tagPrepareOneOfFind
+	case mysqlx.ClientMessages_CRUD_INSERT:
+		typ, tag = mysqlx_prepare.Prepare_OneOfMessage_INSERT, tagPrepareOneOfInsert
+	case mysqlx.ClientMessages_CRUD_UPDATE:
+		typ, tag = mysqlx_prepare.Prepare_OneOfMessage_UPDATE, tagPrepareOneOfUpdate
+	case mysqlx.ClientMessages_CRUD_DELETE:
+		typ, tag = mysqlx_prepare.Prepare_OneOfMessage_DELETE, tagPrepareOneOfDelete
+	default:
+		return p, fmt.Errorf("unable to prepare %s message", mysqlx.ClientMessages_Type(p[i+4]))
+	}
+	m := len(p) - i - 5
+	n0 := 1 + sizeVarint(uint(m)) + m // Crud message field
+	n1 := 2 + n0                      // PrepareOneOf
+	n := 1 + sizeVarint32(id) + 1 + sizeVarint(uint(n1)) + 2 + 1 + sizeVarint(uint(m))
+
+	p = slice.Insert(p, i+5, n)
+	p[i+4] = byte(mysqlx.ClientMessages_PREPARE_PREPARE)
+	j := i + 5
+	p[j] = tagPrepareStmtId<<3 | wireVarint
+	j++
+	j += putUvarint(p[j:], uint64(id))
+	p[j] = tagPrepareStmt<<3 | wireBytes
+	j++
+	j += putUvarint(p[j:], uint64(n1))
+	p[j] = tagPrepareOneOfType<<3 | wireVarint
+	p[j+1] = byte(typ)
+	p[j+2] = tag<<3 | wireBytes
+	j += 3
+	putUvarint(p[j:], uint64(m))
+	binary.LittleEndian.PutUint32(p[i:], uint32(len(p)-i-4))
+	return p, nil
+}
+
 // Execute appends header and mysqlx_prepare.Execute protobuf to execute a prepared statement with id, and given set ofg args.
 func Execute(p []byte, id uint32, args []interface{}) ([]byte, error) {
 	const (
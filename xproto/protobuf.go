@@ -88,3 +88,12 @@ func appendWireBytes(p []byte, tag uint8, value []byte) []byte {
 	p[n-1] &= 0x7F
 	return append(p[:n], value...)
 }
+
+func appendWireUvarint(p []byte, tag uint8, x uint64) []byte {
+	n := sizeVarint64(x)
+	p = append(p, tag<<3|wireVarint, byte(x)|0x80, byte(x>>7)|0x80, byte(x>>14)|0x80, byte(x>>21)|0x80, byte(x>>28)|0x80,
+		byte(x>>35)|0x80, byte(x>>42)|0x80, byte(x>>49)|0x80, byte(x>>56)|0x80, 1)
+	n += len(p) - binary.MaxVarintLen64
+	p[n-1] &= 0x7F
+	return p[:n]
+}
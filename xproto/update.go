@@ -15,22 +15,47 @@ const (
 	tagUpdateUpdateOperation = 7
 	tagUpdateLimit           = 5
 	tagUpdateLimitExpr       = 9
+
+	// Tags from UpdateOperation protobuf
+	tagUpdateOperationSource    = 1
+	tagUpdateOperationOperation = 2
+	tagUpdateOperationValue     = 3
 )
 
-func Update(p []byte, name string, criteria AppendExprFunc) ([]byte, error) {
-	n := len(name)
-	n1 := 1 + sizeVarint(uint(n)) + n // Collection size
+type UpdateType uint8
+
+const (
+	UpdateSet         = UpdateType(mysqlx_crud.UpdateOperation_SET)
+	UpdateItemRemove  = UpdateType(mysqlx_crud.UpdateOperation_ITEM_REMOVE)
+	UpdateItemSet     = UpdateType(mysqlx_crud.UpdateOperation_ITEM_SET)
+	UpdateItemReplace = UpdateType(mysqlx_crud.UpdateOperation_ITEM_REPLACE)
+	UpdateItemMerge   = UpdateType(mysqlx_crud.UpdateOperation_ITEM_MERGE)
+	UpdateArrayInsert = UpdateType(mysqlx_crud.UpdateOperation_ARRAY_INSERT)
+	UpdateArrayAppend = UpdateType(mysqlx_crud.UpdateOperation_ARRAY_APPEND)
+	UpdateMergePatch  = UpdateType(mysqlx_crud.UpdateOperation_MERGE_PATCH)
+)
+
+func Update(p []byte, schema, name string, criteria AppendExprFunc) ([]byte, error) {
+	return update(p, schema, name, mysqlx_crud.DataModel_TABLE, criteria)
+}
+
+// UpdateDocuments appends the header and start of a mysqlx_crud.Update protobuf, for modifying documents in the
+// collection name, in schema or the default schema if empty, that match criteria.
+// Length in the header is left for the caller to fill in, after appending operations.
+func UpdateDocuments(p []byte, schema, name string, criteria AppendExprFunc) ([]byte, error) {
+	return update(p, schema, name, mysqlx_crud.DataModel_DOCUMENT, criteria)
+}
+
+func update(p []byte, schema, name string, model mysqlx_crud.DataModel, criteria AppendExprFunc) ([]byte, error) {
+	n1 := sizeCollection(schema, name)
 	p, b := slice.ForAppend(p, 4+1+3+sizeVarint(uint(n1))+n1)
 	i := 6 + putUvarint(b[6:], uint64(n1))
 	b[4] = byte(mysqlx.ClientMessages_CRUD_UPDATE)
 	b[5] = tagUpdateCollection<<3 | wireBytes
-	b[i] = tagCollectionName<<3 | wireBytes
-	i++
-	i += putUvarint(b[i:], uint64(n))
-	i += copy(b[i:], name)
+	i += putCollection(b[i:], schema, name)
 	b[i] = tagUpdateDataModel<<3 | wireVarint
 	i++
-	b[i] = byte(mysqlx_crud.DataModel_TABLE)
+	b[i] = byte(model)
 	if criteria != nil {
 		return criteria(p, tagUpdateCriteria)
 	}
@@ -38,18 +63,6 @@ func Update(p []byte, name string, criteria AppendExprFunc) ([]byte, error) {
 }
 
 func AppendUpdateSet(p []byte, name string, value interface{}) ([]byte, error) {
-
-	const (
-		tagUpdateOperationSource    = 1
-		tagUpdateOperationOperation = 2
-		tagUpdateOperationValue     = 3
-
-		tagColumnIdentifierDocumentPath = 1
-		tagColumnIdentifierName         = 2
-		tagColumnIdentifierTableName    = 3
-		tagColumnIdentifierSchema       = 4
-	)
-
 	i := len(p)
 	p, err := appendExpr(p, tagUpdateOperationValue, value)
 	if err != nil {
@@ -76,3 +89,37 @@ func AppendUpdateSet(p []byte, name string, value interface{}) ([]byte, error) {
 	p[i] = byte(mysqlx_crud.UpdateOperation_SET)
 	return p, nil
 }
+
+// AppendUpdateOperation appends an UpdateOperation of type op, upon the item at path within the JSON column name, or
+// the document if name is empty, with value, which is ignored for UpdateItemRemove.
+func AppendUpdateOperation(p []byte, op UpdateType, name string, path DocumentPath, value interface{}) ([]byte, error) {
+	i := len(p)
+	p = appendDocumentPath(p, tagColumnIdentifierDocumentPath, path)
+	if name != "" {
+		p = appendWireString(p, tagColumnIdentifierName, name)
+	}
+	p = insertMessageHeader(p, i, tagUpdateOperationSource)
+	p = append(p, tagUpdateOperationOperation<<3|wireVarint, byte(op))
+	if op != UpdateItemRemove {
+		var err error
+		if p, err = appendExpr(p, tagUpdateOperationValue, value); err != nil {
+			return p, err
+		}
+	}
+	return insertMessageHeader(p, i, tagUpdateUpdateOperation), nil
+}
+
+// AppendUpdateOrder appends an Order of the expression expr, descending if desc is true.
+func AppendUpdateOrder(p []byte, expr interface{}, desc bool) ([]byte, error) {
+	return appendOrder(p, tagUpdateOrder, expr, desc)
+}
+
+// AppendUpdateLimit appends a Limit, updating at most rowCount rows. Update does not support an offset.
+func AppendUpdateLimit(p []byte, rowCount uint64) []byte {
+	return appendLimit(p, tagUpdateLimit, rowCount, 0)
+}
+
+// AppendUpdateLimitExpr appends a LimitExpr, updating at most the expression rowCount rows, typically a placeholder.
+func AppendUpdateLimitExpr(p []byte, rowCount interface{}) ([]byte, error) {
+	return appendLimitExpr(p, tagUpdateLimitExpr, rowCount, nil)
+}
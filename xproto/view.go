@@ -0,0 +1,131 @@
+package xproto
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_crud"
+)
+
+const (
+	// Tags from CreateView & ModifyView protobufs
+	tagViewCollection      = 1
+	tagViewDefiner         = 2
+	tagViewAlgorithm       = 3
+	tagViewSecurity        = 4
+	tagViewCheck           = 5
+	tagViewColumn          = 6
+	tagViewStmt            = 7
+	tagViewReplaceExisting = 8
+
+	// Tags from DropView protobuf
+	tagDropViewCollection = 1
+	tagDropViewIfExists   = 2
+)
+
+type ViewAlgorithm uint8
+
+const (
+	ViewAlgorithmDefault   ViewAlgorithm = 0
+	ViewAlgorithmUndefined               = ViewAlgorithm(mysqlx_crud.ViewAlgorithm_UNDEFINED)
+	ViewAlgorithmMerge                   = ViewAlgorithm(mysqlx_crud.ViewAlgorithm_MERGE)
+	ViewAlgorithmTempTable               = ViewAlgorithm(mysqlx_crud.ViewAlgorithm_TEMPTABLE)
+)
+
+type ViewSecurity uint8
+
+const (
+	ViewSecurityDefault ViewSecurity = 0
+	ViewSecurityInvoker              = ViewSecurity(mysqlx_crud.ViewSqlSecurity_INVOKER)
+	ViewSecurityDefiner              = ViewSecurity(mysqlx_crud.ViewSqlSecurity_DEFINER)
+)
+
+type ViewCheckOption uint8
+
+const (
+	ViewCheckNone     ViewCheckOption = 0
+	ViewCheckLocal                    = ViewCheckOption(mysqlx_crud.ViewCheckOption_LOCAL)
+	ViewCheckCascaded                 = ViewCheckOption(mysqlx_crud.ViewCheckOption_CASCADED)
+)
+
+// View is the definition of a view, zero values are left for the server to default, or unmodified.
+type View struct {
+	Definer   string
+	Algorithm ViewAlgorithm
+	Security  ViewSecurity
+	Check     ViewCheckOption
+	Columns   []string
+}
+
+// CreateView converts the Find message appended at p[i:] into a CreateView message, of view name in schema, or the
+// default schema if empty, replacing any existing view if replace is true.
+func CreateView(p []byte, i int, schema, name string, v View, replace bool) ([]byte, error) {
+	if len(p) < i+5 {
+		return p, errors.New("no find message to create view from")
+	}
+	p, err := view(p, i, mysqlx.ClientMessages_CRUD_CREATE_VIEW, schema, name, v)
+	if err != nil {
+		return p, err
+	}
+	if replace {
+		p = append(p, tagViewReplaceExisting<<3|wireVarint, 1)
+		binary.LittleEndian.PutUint32(p[i:], uint32(len(p)-i-4))
+	}
+	return p, nil
+}
+
+// ModifyView converts the Find message appended at p[i:], if any, into a ModifyView message, of view name in schema,
+// or the default schema if empty.
+func ModifyView(p []byte, i int, schema, name string, v View) ([]byte, error) {
+	return view(p, i, mysqlx.ClientMessages_CRUD_MODIFY_VIEW, schema, name, v)
+}
+
+func view(p []byte, i int, typ mysqlx.ClientMessages_Type, schema, name string, v View) ([]byte, error) {
+	var stmt []byte
+
+	if len(p) > i {
+		if len(p) < i+5 {
+			return p, errors.New("incomplete message to create view from")
+		}
+		if mysqlx.ClientMessages_Type(p[i+4]) != mysqlx.ClientMessages_CRUD_FIND {
+			return p, fmt.Errorf("unable to create view from %s message", mysqlx.ClientMessages_Type(p[i+4]))
+		}
+		stmt = append(stmt, p[i+5:]...)
+	}
+	p = append(p[:i], 0, 0, 0, 0, byte(typ))
+	p = appendCollection(p, tagViewCollection, schema, name)
+	if v.Definer != "" {
+		p = appendWireString(p, tagViewDefiner, v.Definer)
+	}
+	if v.Algorithm != ViewAlgorithmDefault {
+		p = append(p, tagViewAlgorithm<<3|wireVarint, byte(v.Algorithm))
+	}
+	if v.Security != ViewSecurityDefault {
+		p = append(p, tagViewSecurity<<3|wireVarint, byte(v.Security))
+	}
+	if v.Check != ViewCheckNone {
+		p = append(p, tagViewCheck<<3|wireVarint, byte(v.Check))
+	}
+	for _, column := range v.Columns {
+		p = appendWireString(p, tagViewColumn, column)
+	}
+	if stmt != nil {
+		p = appendWireBytes(p, tagViewStmt, stmt)
+	}
+	binary.LittleEndian.PutUint32(p[i:], uint32(len(p)-i-4))
+	return p, nil
+}
+
+// DropView appends a DropView message, of view name in schema, or the default schema if empty.
+func DropView(p []byte, schema, name string, ifExists bool) []byte {
+	i := len(p)
+	p = append(p, 0, 0, 0, 0, byte(mysqlx.ClientMessages_CRUD_DROP_VIEW))
+	p = appendCollection(p, tagDropViewCollection, schema, name)
+	if ifExists {
+		p = append(p, tagDropViewIfExists<<3|wireVarint, 1)
+	}
+	binary.LittleEndian.PutUint32(p[i:], uint32(len(p)-i-4))
+	return p
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 
 	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_crud"
 	"github.com/renthraysk/xtorm/slice"
 )
 
@@ -44,33 +45,60 @@ func StmtExecute(p []byte, stmt string, args []interface{}) ([]byte, error) {
 	return p, nil
 }
 
-func Delete(p []byte, name string, criteria AppendExprFunc) ([]byte, error) {
-	const (
-		tagDeleteCollection = 1
-		tagDeleteDataModel  = 2
-		tagDeleteCriteria   = 3
-	)
-	const (
-		tagCollectionName   = 1
-		tagCollectionSchema = 2
-	)
+const (
+	tagDeleteCollection = 1
+	tagDeleteDataModel  = 2
+	tagDeleteCriteria   = 3
+	tagDeleteLimit      = 4
+	tagDeleteOrder      = 5
+	tagDeleteArgs       = 6
+	tagDeleteLimitExpr  = 7
+)
+
+func Delete(p []byte, schema, name string, criteria AppendExprFunc) ([]byte, error) {
+	return deleteFrom(p, schema, name, mysqlx_crud.DataModel_TABLE, criteria)
+}
+
+// DeleteDocuments appends a mysqlx_crud.Delete protobuf, for removing documents from the collection name, in schema or
+// the default schema if empty, that match criteria.
+func DeleteDocuments(p []byte, schema, name string, criteria AppendExprFunc) ([]byte, error) {
+	return deleteFrom(p, schema, name, mysqlx_crud.DataModel_DOCUMENT, criteria)
+}
+
+func deleteFrom(p []byte, schema, name string, model mysqlx_crud.DataModel, criteria AppendExprFunc) ([]byte, error) {
 	var err error
 
 	s := len(p)
 
-	n := len(name)
-	n1 := 1 + sizeVarint(uint(n)) + n // mysqlx.Collection
-	p, b := slice.ForAppend(p, 4+1+1+sizeVarint(uint(n1))+n1)
+	n1 := sizeCollection(schema, name) // mysqlx.Collection
+	p, b := slice.ForAppend(p, 4+1+3+sizeVarint(uint(n1))+n1)
 	i := 6 + putUvarint(b[6:], uint64(n1))
 	b[4] = byte(mysqlx.ClientMessages_CRUD_DELETE)
 	b[5] = tagDeleteCollection<<3 | wireBytes
-	b[i] = tagCollectionName<<3 | wireBytes
-	i++
-	i += putUvarint(b[i:], uint64(n))
-	copy(b[i:], name)
+	i += putCollection(b[i:], schema, name)
+	b[i] = tagDeleteDataModel<<3 | wireVarint
+	b[i+1] = byte(model)
 	if criteria != nil {
 		p, err = criteria(p, tagDeleteCriteria)
 	}
 	binary.LittleEndian.PutUint32(p[s:], uint32(len(p)-s-4))
 	return p, err
 }
+
+// AppendDeleteOrder appends an Order of the expression expr, descending if desc is true.
+// Length in the header of the Delete must be updated by the caller.
+func AppendDeleteOrder(p []byte, expr interface{}, desc bool) ([]byte, error) {
+	return appendOrder(p, tagDeleteOrder, expr, desc)
+}
+
+// AppendDeleteLimit appends a Limit, deleting at most rowCount rows. Delete does not support an offset.
+// Length in the header of the Delete must be updated by the caller.
+func AppendDeleteLimit(p []byte, rowCount uint64) []byte {
+	return appendLimit(p, tagDeleteLimit, rowCount, 0)
+}
+
+// AppendDeleteLimitExpr appends a LimitExpr, deleting at most the expression rowCount rows, typically a placeholder.
+// Length in the header of the Delete must be updated by the caller.
+func AppendDeleteLimitExpr(p []byte, rowCount interface{}) ([]byte, error) {
+	return appendLimitExpr(p, tagDeleteLimitExpr, rowCount, nil)
+}
@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 
 	"github.com/renthraysk/xtorm/protobuf/mysqlx"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_connection"
 	"github.com/renthraysk/xtorm/protobuf/mysqlx_crud"
+	"github.com/renthraysk/xtorm/protobuf/mysqlx_cursor"
 	"github.com/renthraysk/xtorm/protobuf/mysqlx_datatypes"
 	"github.com/renthraysk/xtorm/protobuf/mysqlx_expr"
 	"github.com/renthraysk/xtorm/protobuf/mysqlx_prepare"
@@ -201,6 +204,61 @@ func TestAnyLiterals(t *testing.T) {
 	}
 }
 
+func TestAnyStructured(t *testing.T) {
+	const tag = 1
+
+	unmarshal := func(t *testing.T, v interface{}) *mysqlx_datatypes.Any {
+		var any mysqlx_datatypes.Any
+
+		b, err := appendAny(nil, tag, v)
+		if err != nil {
+			t.Fatalf("failed to marshal any: %s", err)
+		}
+		n, nn := binary.Uvarint(b[1:])
+		if uint64(len(b)) != 1+uint64(nn)+n {
+			t.Fatalf("length incorrect, encoded %d, got %d", 1+uint64(nn)+n, len(b))
+		}
+		if err := proto.Unmarshal(b[1+nn:], &any); err != nil {
+			t.Fatalf("unmarshalling any failed: %s", err)
+		}
+		return &any
+	}
+
+	any := unmarshal(t, map[string]interface{}{"b": []interface{}{1, "two"}, "a": map[string]interface{}{"c": true}, "d": nil})
+	if any.GetType() != mysqlx_datatypes.Any_OBJECT || len(any.GetObj().GetFld()) != 3 {
+		t.Fatalf("expected object of 3 fields, got %v", any)
+	}
+	fields := any.GetObj().GetFld()
+	if fields[0].GetKey() != "a" || fields[1].GetKey() != "b" || fields[2].GetKey() != "d" {
+		t.Fatalf("expected fields sorted by key, got %v", fields)
+	}
+	if !fields[0].GetValue().GetObj().GetFld()[0].GetValue().GetScalar().GetVBool() {
+		t.Fatalf("unexpected nested object %v", fields[0].GetValue())
+	}
+	array := fields[1].GetValue().GetArray().GetValue()
+	if len(array) != 2 || array[0].GetScalar().GetVSignedInt() != 1 || string(array[1].GetScalar().GetVString().GetValue()) != "two" {
+		t.Fatalf("unexpected nested array %v", fields[1].GetValue())
+	}
+	if fields[2].GetValue().GetScalar().GetType() != mysqlx_datatypes.Scalar_V_NULL {
+		t.Fatalf("unexpected null %v", fields[2].GetValue())
+	}
+
+	any = unmarshal(t, AnyArray([]string{"x", "y", "z"}))
+	if any.GetType() != mysqlx_datatypes.Any_ARRAY || len(any.GetArray().GetValue()) != 3 {
+		t.Fatalf("expected array of 3 values, got %v", any)
+	}
+	any = unmarshal(t, AnyObject(map[string]int{"one": 1}))
+	if any.GetType() != mysqlx_datatypes.Any_OBJECT || any.GetObj().GetFld()[0].GetValue().GetScalar().GetVSignedInt() != 1 {
+		t.Fatalf("expected object, got %v", any)
+	}
+	if _, err := appendAny(nil, tag, []string{"x"}); err == nil {
+		t.Fatal("expected error for slice without opting in")
+	}
+	if _, err := appendAny(nil, tag, AnyObject([]string{"x"})); err == nil {
+		t.Fatal("expected error for non map object")
+	}
+}
+
 func TestExprLiterals(t *testing.T) {
 	const tag = 1
 
@@ -555,6 +613,96 @@ func TestPrepare(t *testing.T) {
 	}
 }
 
+func TestPrepareCrud(t *testing.T) {
+	placeholder := func(pos uint32) AppendExprFunc {
+		return func(p []byte, tag uint8) ([]byte, error) {
+			return AppendExprPlaceholder(p, tag, pos)
+		}
+	}
+	criteria := func(p []byte, tag uint8) ([]byte, error) {
+		return AppendExprOperator(p, tag, "==", []interface{}{
+			AppendExprFunc(func(p []byte, tag uint8) ([]byte, error) { return AppendExprColumn(p, tagOperatorParam, "id") }),
+			placeholder(0)})
+	}
+	long := strings.Repeat("abcdefghijklmnopqrstuvwxyz", 10)
+
+	tests := []struct {
+		id       uint32
+		typ      mysqlx_prepare.Prepare_OneOfMessage_Type
+		generate func(p []byte) ([]byte, error)
+	}{
+		{1, mysqlx_prepare.Prepare_OneOfMessage_FIND, func(p []byte) ([]byte, error) { return Find(p, "", "foo", criteria) }},
+		{142, mysqlx_prepare.Prepare_OneOfMessage_INSERT, func(p []byte) ([]byte, error) {
+			return AppendInsertRow(Insert(p, "", long, []string{"id", "val"}), []interface{}{placeholder(0), placeholder(1)})
+		}},
+		{math.MaxUint32, mysqlx_prepare.Prepare_OneOfMessage_UPDATE, func(p []byte) ([]byte, error) {
+			p, err := Update(p, "", "foo", criteria)
+			if err != nil {
+				return p, err
+			}
+			return AppendUpdateSet(p, "val", placeholder(1))
+		}},
+		{3, mysqlx_prepare.Prepare_OneOfMessage_DELETE, func(p []byte) ([]byte, error) { return Delete(p, "", "foo", criteria) }},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var p mysqlx_prepare.Prepare
+
+			// Preceding message, to check preparing at an offset
+			b := Deallocate(nil, 1)
+			n := len(b)
+			b, err := tt.generate(b)
+			if err != nil {
+				t.Fatalf("failed to marshal crud: %s", err)
+			}
+			binary.LittleEndian.PutUint32(b[n:], uint32(len(b)-n-4))
+			if b, err = PrepareCrud(b, n, tt.id); err != nil {
+				t.Fatalf("failed to prepare crud: %s", err)
+			}
+			b = b[n:]
+			if int(binary.LittleEndian.Uint32(b)) != len(b)-4 {
+				t.Fatalf("incorrect size %d vs %d", len(b)-4, binary.LittleEndian.Uint32(b))
+			}
+			if b[4] != byte(mysqlx.ClientMessages_PREPARE_PREPARE) {
+				t.Fatal("incorrect clientmessage type")
+			}
+			if err := proto.Unmarshal(b[5:], &p); err != nil {
+				t.Fatalf("unmarshal failed: %q", err)
+			}
+			if p.GetStmtId() != tt.id {
+				t.Fatalf("GetStmtId() failed")
+			}
+			if p.GetStmt().GetType() != tt.typ {
+				t.Fatalf("GetStmt().GetType() expected %s, got %s", tt.typ, p.GetStmt().GetType())
+			}
+			switch tt.typ {
+			case mysqlx_prepare.Prepare_OneOfMessage_FIND:
+				if p.GetStmt().GetFind().GetCriteria().GetOperator().GetParam()[1].GetPosition() != 0 {
+					t.Fatal("incorrect find placeholder")
+				}
+			case mysqlx_prepare.Prepare_OneOfMessage_INSERT:
+				if p.GetStmt().GetInsert().GetCollection().GetName() != long ||
+					p.GetStmt().GetInsert().GetRow()[0].GetField()[1].GetPosition() != 1 {
+					t.Fatal("incorrect insert")
+				}
+			case mysqlx_prepare.Prepare_OneOfMessage_UPDATE:
+				if p.GetStmt().GetUpdate().GetOperation()[0].GetValue().GetPosition() != 1 {
+					t.Fatal("incorrect update placeholder")
+				}
+			case mysqlx_prepare.Prepare_OneOfMessage_DELETE:
+				if p.GetStmt().GetDelete().GetCollection().GetName() != "foo" {
+					t.Fatal("incorrect delete")
+				}
+			}
+		})
+	}
+
+	if _, err := PrepareCrud(Reset(nil, false), 0, 1); err == nil {
+		t.Fatal("expected error preparing non crud message")
+	}
+}
+
 func TestExecute(t *testing.T) {
 	tests := []struct {
 		id   uint32
@@ -615,13 +763,20 @@ func TestDeallocate(t *testing.T) {
 }
 
 func TestDelete(t *testing.T) {
-	tests := []string{"foo", "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz"}
+	tests := []struct {
+		schema string
+		name   string
+	}{
+		{"", "foo"},
+		{"", "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz"},
+		{"bar", "foo"},
+	}
 
-	for i, name := range tests {
+	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			var d mysqlx_crud.Delete
 
-			b, err := Delete(nil, name, nil)
+			b, err := Delete(nil, tt.schema, tt.name, nil)
 			if err != nil {
 				t.Fatalf("failed to marshal delete: %s", err)
 			}
@@ -634,13 +789,190 @@ func TestDelete(t *testing.T) {
 			if err := proto.Unmarshal(b[5:], &d); err != nil {
 				t.Fatalf("unmarshal failed: %s", err)
 			}
-			if d.GetCollection().GetName() != name {
+			if d.GetCollection().GetName() != tt.name {
 				t.Fatalf("incorrect result from Collection.GetName()")
 			}
+			if d.GetCollection().GetSchema() != tt.schema {
+				t.Fatalf("incorrect result from Collection.GetSchema()")
+			}
 		})
 	}
 }
 
+func TestSchemaQualified(t *testing.T) {
+	var i mysqlx_crud.Insert
+	var u mysqlx_crud.Update
+	var f mysqlx_crud.Find
+
+	b := Insert(nil, "bar", "foo", []string{"id"})
+	if err := proto.Unmarshal(b[5:], &i); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if i.GetCollection().GetSchema() != "bar" || i.GetCollection().GetName() != "foo" || i.GetProjection()[0].GetName() != "id" {
+		t.Fatalf("incorrect insert collection %v", i.GetCollection())
+	}
+
+	b, err := Update(nil, "bar", "foo", nil)
+	if err != nil {
+		t.Fatalf("failed to marshal update: %s", err)
+	}
+	if b, err = AppendUpdateSet(b, "val", 1); err != nil {
+		t.Fatalf("failed to append set: %s", err)
+	}
+	if err := proto.Unmarshal(b[5:], &u); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if u.GetCollection().GetSchema() != "bar" || u.GetCollection().GetName() != "foo" || len(u.GetOperation()) != 1 {
+		t.Fatalf("incorrect update collection %v", u.GetCollection())
+	}
+
+	b, err = Find(nil, "bar", "foo", func(p []byte, tag uint8) ([]byte, error) {
+		return AppendExprQualifiedColumn(p, tag, "bar", "foo", "id")
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal find: %s", err)
+	}
+	if err := proto.Unmarshal(b[5:], &f); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if f.GetCollection().GetSchema() != "bar" || f.GetCollection().GetName() != "foo" || f.GetDataModel() != mysqlx_crud.DataModel_TABLE {
+		t.Fatalf("incorrect find collection %v", f.GetCollection())
+	}
+	id := f.GetCriteria().GetIdentifier()
+	if id.GetSchemaName() != "bar" || id.GetTableName() != "foo" || id.GetName() != "id" {
+		t.Fatalf("incorrect column identifier %v", id)
+	}
+}
+
+func TestOrderLimit(t *testing.T) {
+	var u mysqlx_crud.Update
+	var d mysqlx_crud.Delete
+
+	column := func(name string) AppendExprFunc {
+		return func(p []byte, tag uint8) ([]byte, error) {
+			return AppendExprColumn(p, tag, name)
+		}
+	}
+	placeholder := func(pos uint32) AppendExprFunc {
+		return func(p []byte, tag uint8) ([]byte, error) {
+			return AppendExprPlaceholder(p, tag, pos)
+		}
+	}
+
+	b, err := Update(nil, "", "foo", nil)
+	if err != nil {
+		t.Fatalf("failed to marshal update: %s", err)
+	}
+	if b, err = AppendUpdateSet(b, "val", 1); err != nil {
+		t.Fatalf("failed to append set: %s", err)
+	}
+	if b, err = AppendUpdateOrder(b, column("id"), true); err != nil {
+		t.Fatalf("failed to append order: %s", err)
+	}
+	b = AppendUpdateLimit(b, 1000)
+	if err := proto.Unmarshal(b[5:], &u); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if len(u.GetOrder()) != 1 || u.GetOrder()[0].GetDirection() != mysqlx_crud.Order_DESC ||
+		u.GetOrder()[0].GetExpr().GetIdentifier().GetName() != "id" {
+		t.Fatalf("incorrect update order %v", u.GetOrder())
+	}
+	if u.GetLimit().GetRowCount() != 1000 || u.Limit.Offset != nil {
+		t.Fatalf("incorrect update limit %v", u.GetLimit())
+	}
+
+	b, err = Delete(nil, "", "foo", nil)
+	if err != nil {
+		t.Fatalf("failed to marshal delete: %s", err)
+	}
+	if b, err = AppendDeleteOrder(b, column("created"), false); err != nil {
+		t.Fatalf("failed to append order: %s", err)
+	}
+	if b, err = AppendDeleteLimitExpr(b, placeholder(0)); err != nil {
+		t.Fatalf("failed to append limit expression: %s", err)
+	}
+	binary.LittleEndian.PutUint32(b, uint32(len(b)-4))
+	if err := proto.Unmarshal(b[5:], &d); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if len(d.GetOrder()) != 1 || d.GetOrder()[0].GetDirection() != mysqlx_crud.Order_ASC ||
+		d.GetOrder()[0].GetExpr().GetIdentifier().GetName() != "created" {
+		t.Fatalf("incorrect delete order %v", d.GetOrder())
+	}
+	if d.GetLimitExpr().GetRowCount().GetType() != mysqlx_expr.Expr_PLACEHOLDER {
+		t.Fatalf("incorrect delete limit expression %v", d.GetLimitExpr())
+	}
+
+	if b, err = Delete(b[:0], "", "foo", nil); err != nil {
+		t.Fatalf("failed to marshal delete: %s", err)
+	}
+	b = AppendDeleteLimit(b, 10)
+	binary.LittleEndian.PutUint32(b, uint32(len(b)-4))
+	if err := proto.Unmarshal(b[5:], &d); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if d.GetLimit().GetRowCount() != 10 {
+		t.Fatalf("incorrect delete limit %v", d.GetLimit())
+	}
+}
+
+func TestUpdateOperation(t *testing.T) {
+	var u mysqlx_crud.Update
+
+	path := DocumentPath{{Type: DocumentPathMember, Value: "tags"}, {Type: DocumentPathArrayIndex, Index: 2}}
+	tests := []struct {
+		op    UpdateType
+		name  string
+		path  DocumentPath
+		value interface{}
+	}{
+		{UpdateItemSet, "doc", path, "a"},
+		{UpdateItemRemove, "doc", path, nil},
+		{UpdateItemReplace, "doc", path[:1], 1},
+		{UpdateArrayAppend, "doc", path[:1], "b"},
+		{UpdateArrayInsert, "doc", path, "c"},
+		{UpdateMergePatch, "", nil, "{}"},
+	}
+
+	b, err := Update(nil, "", "foo", nil)
+	if err != nil {
+		t.Fatalf("failed to marshal update: %s", err)
+	}
+	for _, tt := range tests {
+		if b, err = AppendUpdateOperation(b, tt.op, tt.name, tt.path, tt.value); err != nil {
+			t.Fatalf("failed to append update operation: %s", err)
+		}
+	}
+	if err := proto.Unmarshal(b[5:], &u); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if len(u.GetOperation()) != len(tests) {
+		t.Fatalf("expected %d operations, got %d", len(tests), len(u.GetOperation()))
+	}
+	for i, tt := range tests {
+		op := u.GetOperation()[i]
+		if op.GetOperation() != mysqlx_crud.UpdateOperation_UpdateType(tt.op) {
+			t.Fatalf("operation %d: expected %d, got %s", i, tt.op, op.GetOperation())
+		}
+		if op.GetSource().GetName() != tt.name {
+			t.Fatalf("operation %d: incorrect name %q", i, op.GetSource().GetName())
+		}
+		items := op.GetSource().GetDocumentPath()
+		if len(items) != len(tt.path) {
+			t.Fatalf("operation %d: expected %d path items, got %d", i, len(tt.path), len(items))
+		}
+		for j, item := range items {
+			if item.GetType() != mysqlx_expr.DocumentPathItem_Type(tt.path[j].Type) || item.GetValue() != tt.path[j].Value ||
+				item.GetIndex() != tt.path[j].Index {
+				t.Fatalf("operation %d: incorrect path item %d %v", i, j, item)
+			}
+		}
+		if (op.Value == nil) != (tt.op == UpdateItemRemove) {
+			t.Fatalf("operation %d: unexpected value %v", i, op.Value)
+		}
+	}
+}
+
 func TestInsert(t *testing.T) {
 
 	tests := []struct {
@@ -659,7 +991,7 @@ func TestInsert(t *testing.T) {
 		t.Run(strconv.Itoa(j), func(t *testing.T) {
 			var i mysqlx_crud.Insert
 
-			b := Insert(nil, tt.name, tt.columns)
+			b := Insert(nil, "", tt.name, tt.columns)
 			for _, r := range tt.rows {
 				var err error
 				b, err = AppendInsertRow(b, r)
@@ -735,7 +1067,7 @@ func TestUpdate(t *testing.T) {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			var u mysqlx_crud.Update
 
-			b, err := Update(nil, tt.name, tt.criteria)
+			b, err := Update(nil, "", tt.name, tt.criteria)
 			if err != nil {
 				t.Fatalf("update criteria expression: %s", err)
 			}
@@ -842,3 +1174,310 @@ func TestStmtExecute(t *testing.T) {
 		})
 	}
 }
+
+func TestFind(t *testing.T) {
+	column := func(name string) AppendExprFunc {
+		return func(p []byte, tag uint8) ([]byte, error) {
+			return AppendExprColumn(p, tag, name)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		criteria AppendExprFunc
+		columns  []string
+		alias    string
+		order    string
+		desc     bool
+		group    string
+		rowCount uint64
+		offset   uint64
+	}{
+		// SELECT * FROM foo
+		{name: "foo"},
+
+		// SELECT id, val AS alias FROM foo WHERE id == 2 ORDER BY id DESC LIMIT 10
+		{name: "foo", criteria: func(p []byte, tag uint8) ([]byte, error) {
+			return AppendExprOperator(p, tag, "==", []interface{}{column("id"), 2})
+		}, columns: []string{"id", "val"}, alias: "alias", order: "id", desc: true, rowCount: 10},
+
+		// SELECT val FROM foo GROUP BY val ORDER BY val LIMIT 300, 200
+		{name: "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz",
+			columns: []string{"val"}, order: "val", group: "val", rowCount: 200, offset: 300},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var f mysqlx_crud.Find
+
+			b, err := Find(nil, "", tt.name, tt.criteria)
+			if err != nil {
+				t.Fatalf("find criteria expression: %s", err)
+			}
+			for j, name := range tt.columns {
+				alias := ""
+				if j == len(tt.columns)-1 {
+					alias = tt.alias
+				}
+				if b, err = AppendFindProjection(b, column(name), alias); err != nil {
+					t.Fatalf("failed to append projection: %s", err)
+				}
+			}
+			if tt.group != "" {
+				if b, err = AppendFindGrouping(b, column(tt.group)); err != nil {
+					t.Fatalf("failed to append grouping: %s", err)
+				}
+			}
+			if tt.order != "" {
+				if b, err = AppendFindOrder(b, column(tt.order), tt.desc); err != nil {
+					t.Fatalf("failed to append order: %s", err)
+				}
+			}
+			if tt.rowCount > 0 {
+				b = AppendFindLimit(b, tt.rowCount, tt.offset)
+			}
+
+			if b[4] != byte(mysqlx.ClientMessages_CRUD_FIND) {
+				t.Fatal("incorrect clientmessage type")
+			}
+			if err := proto.Unmarshal(b[5:], &f); err != nil {
+				t.Fatalf("unmarshal failed: %s", err)
+			}
+			if f.GetDataModel() != mysqlx_crud.DataModel_TABLE {
+				t.Fatal("incorrect result from GetDataModel")
+			}
+			if f.GetCollection().GetName() != tt.name {
+				t.Fatal("incorrect result from Collection.GetName()")
+			}
+			if (tt.criteria != nil) != (f.GetCriteria() != nil) {
+				t.Fatal("incorrect criteria")
+			}
+			if len(f.GetProjection()) != len(tt.columns) {
+				t.Fatalf("incorrect number of columns, expected %d, got %d", len(tt.columns), len(f.GetProjection()))
+			}
+			for j, name := range tt.columns {
+				if f.GetProjection()[j].GetSource().GetIdentifier().GetName() != name {
+					t.Fatalf("incorrect column name, expected %s, got %s", name, f.GetProjection()[j].GetSource().GetIdentifier().GetName())
+				}
+			}
+			if len(tt.columns) > 0 && f.GetProjection()[len(tt.columns)-1].GetAlias() != tt.alias {
+				t.Fatalf("incorrect alias, expected %q, got %q", tt.alias, f.GetProjection()[len(tt.columns)-1].GetAlias())
+			}
+			if tt.group != "" && (len(f.GetGrouping()) != 1 || f.GetGrouping()[0].GetIdentifier().GetName() != tt.group) {
+				t.Fatal("incorrect grouping")
+			}
+			if tt.order != "" {
+				if len(f.GetOrder()) != 1 || f.GetOrder()[0].GetExpr().GetIdentifier().GetName() != tt.order {
+					t.Fatal("incorrect order")
+				}
+				if (f.GetOrder()[0].GetDirection() == mysqlx_crud.Order_DESC) != tt.desc {
+					t.Fatal("incorrect order direction")
+				}
+			}
+			if f.GetLimit().GetRowCount() != tt.rowCount || f.GetLimit().GetOffset() != tt.offset {
+				t.Fatalf("incorrect limit, expected %d,%d, got %d,%d", tt.offset, tt.rowCount, f.GetLimit().GetOffset(), f.GetLimit().GetRowCount())
+			}
+		})
+	}
+}
+
+func TestFindLocking(t *testing.T) {
+	tests := []struct {
+		lock    RowLock
+		options RowLockOptions
+	}{
+		{RowLockShared, RowLockWait},
+		{RowLockExclusive, RowLockWait},
+		{RowLockExclusive, RowLockNoWait},
+		{RowLockExclusive, RowLockSkipLocked},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var f mysqlx_crud.Find
+
+			b, err := Find(nil, "", "foo", nil)
+			if err != nil {
+				t.Fatalf("failed to marshal find: %s", err)
+			}
+			b = AppendFindLocking(b, tt.lock, tt.options)
+			if err := proto.Unmarshal(b[5:], &f); err != nil {
+				t.Fatalf("unmarshal failed: %s", err)
+			}
+			if f.GetLocking() != mysqlx_crud.Find_RowLock(tt.lock) {
+				t.Fatalf("incorrect locking, expected %d, got %s", tt.lock, f.GetLocking())
+			}
+			if tt.options == RowLockWait {
+				if f.LockingOptions != nil {
+					t.Fatal("unexpected locking options")
+				}
+			} else if f.GetLockingOptions() != mysqlx_crud.Find_RowLockOptions(tt.options) {
+				t.Fatalf("incorrect locking options, expected %d, got %s", tt.options, f.GetLockingOptions())
+			}
+		})
+	}
+}
+
+func TestFindLimitExpr(t *testing.T) {
+	placeholder := func(pos uint32) AppendExprFunc {
+		return func(p []byte, tag uint8) ([]byte, error) {
+			return AppendExprPlaceholder(p, tag, pos)
+		}
+	}
+	tests := []struct {
+		rowCount interface{}
+		offset   interface{}
+	}{
+		{placeholder(0), nil},
+		{placeholder(0), placeholder(1)},
+		{10, placeholder(0)},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var f mysqlx_crud.Find
+
+			b, err := Find(nil, "", "foo", nil)
+			if err != nil {
+				t.Fatalf("failed to marshal find: %s", err)
+			}
+			if b, err = AppendFindLimitExpr(b, tt.rowCount, tt.offset); err != nil {
+				t.Fatalf("failed to append limit expression: %s", err)
+			}
+			if err := proto.Unmarshal(b[5:], &f); err != nil {
+				t.Fatalf("unmarshal failed: %s", err)
+			}
+			if f.Limit != nil || f.GetLimitExpr().GetRowCount() == nil {
+				t.Fatalf("incorrect limit expression %v", f.GetLimitExpr())
+			}
+			if (tt.offset != nil) != (f.GetLimitExpr().GetOffset() != nil) {
+				t.Fatalf("incorrect limit expression offset %v", f.GetLimitExpr().GetOffset())
+			}
+			if tt.offset != nil && f.GetLimitExpr().GetOffset().GetType() != mysqlx_expr.Expr_PLACEHOLDER {
+				t.Fatalf("incorrect limit expression offset %v", f.GetLimitExpr().GetOffset())
+			}
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	tests := []struct {
+		cursorID  uint32
+		stmtID    uint32
+		args      []interface{}
+		fetchRows uint64
+	}{
+		{1, 1, nil, 0},
+		{1, 42, []interface{}{1, "abc"}, 100},
+		{math.MaxUint32, math.MaxUint32, []interface{}{strconv.Itoa(math.MaxInt32)}, math.MaxUint64},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var o mysqlx_cursor.Open
+			var f mysqlx_cursor.Fetch
+			var c mysqlx_cursor.Close
+
+			b, err := CursorOpen(nil, tt.cursorID, tt.stmtID, tt.args, tt.fetchRows)
+			if err != nil {
+				t.Fatalf("failed to marshal cursor open: %s", err)
+			}
+			if int(binary.LittleEndian.Uint32(b)) != len(b)-4 {
+				t.Fatalf("incorrect size %d vs %d", len(b)-4, binary.LittleEndian.Uint32(b))
+			}
+			if b[4] != byte(mysqlx.ClientMessages_CURSOR_OPEN) {
+				t.Fatal("incorrect clientmessage type")
+			}
+			if err := proto.Unmarshal(b[5:], &o); err != nil {
+				t.Fatalf("unmarshal failed: %s", err)
+			}
+			if o.GetCursorId() != tt.cursorID || o.GetFetchRows() != tt.fetchRows {
+				t.Fatalf("incorrect cursor open %v", &o)
+			}
+			if o.GetStmt().GetType() != mysqlx_cursor.Open_OneOfMessage_PREPARE_EXECUTE ||
+				o.GetStmt().GetPrepareExecute().GetStmtId() != tt.stmtID ||
+				len(o.GetStmt().GetPrepareExecute().GetArgs()) != len(tt.args) {
+				t.Fatalf("incorrect cursor open statement %v", o.GetStmt())
+			}
+
+			b = CursorFetch(nil, tt.cursorID, tt.fetchRows)
+			if int(binary.LittleEndian.Uint32(b)) != len(b)-4 {
+				t.Fatalf("incorrect size %d vs %d", len(b)-4, binary.LittleEndian.Uint32(b))
+			}
+			if b[4] != byte(mysqlx.ClientMessages_CURSOR_FETCH) {
+				t.Fatal("incorrect clientmessage type")
+			}
+			if err := proto.Unmarshal(b[5:], &f); err != nil {
+				t.Fatalf("unmarshal failed: %s", err)
+			}
+			if f.GetCursorId() != tt.cursorID || f.GetFetchRows() != tt.fetchRows {
+				t.Fatalf("incorrect cursor fetch %v", &f)
+			}
+
+			b = CursorClose(nil, tt.cursorID)
+			if int(binary.LittleEndian.Uint32(b)) != len(b)-4 {
+				t.Fatalf("incorrect size %d vs %d", len(b)-4, binary.LittleEndian.Uint32(b))
+			}
+			if b[4] != byte(mysqlx.ClientMessages_CURSOR_CLOSE) {
+				t.Fatal("incorrect clientmessage type")
+			}
+			if err := proto.Unmarshal(b[5:], &c); err != nil {
+				t.Fatalf("unmarshal failed: %s", err)
+			}
+			if c.GetCursorId() != tt.cursorID {
+				t.Fatalf("incorrect cursor close %v", &c)
+			}
+		})
+	}
+}
+
+func TestCapabilitiesSet(t *testing.T) {
+	b, err := CapabilitiesSet([]byte("prefix"), Object{
+		{Key: "tls", Value: true},
+		{Key: "compression", Value: Object{{Key: "algorithm", Value: "deflate_stream"}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to append CapabilitiesSet: %s", err)
+	}
+	if int(binary.LittleEndian.Uint32(b)) != len(b)-4 {
+		t.Fatalf("incorrect size %d vs %d", len(b)-4, binary.LittleEndian.Uint32(b))
+	}
+	if b[4] != byte(mysqlx.ClientMessages_CON_CAPABILITIES_SET) {
+		t.Fatal("incorrect clientmessage type")
+	}
+	var cs mysqlx_connection.CapabilitiesSet
+	if err := proto.Unmarshal(b[5:], &cs); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	caps := cs.GetCapabilities().GetCapabilities()
+	if len(caps) != 2 || caps[0].GetName() != "tls" || !caps[0].GetValue().GetScalar().GetVBool() {
+		t.Fatalf("incorrect capabilities %v", &cs)
+	}
+	fields := caps[1].GetValue().GetObj().GetFld()
+	if caps[1].GetName() != "compression" || len(fields) != 1 || fields[0].GetKey() != "algorithm" ||
+		string(fields[0].GetValue().GetScalar().GetVString().GetValue()) != "deflate_stream" {
+		t.Fatalf("incorrect compression capability %v", caps[1])
+	}
+
+	if _, err := CapabilitySet(nil, "tls", struct{}{}); err == nil {
+		t.Fatal("expected error setting unsupported capability value")
+	}
+}
+
+func TestCompression(t *testing.T) {
+	payload := []byte("compressed")
+	b := Compression([]byte("prefix"), 1024, payload)[len("prefix"):]
+	if int(binary.LittleEndian.Uint32(b)) != len(b)-4 {
+		t.Fatalf("incorrect size %d vs %d", len(b)-4, binary.LittleEndian.Uint32(b))
+	}
+	if b[4] != byte(mysqlx.ClientMessages_COMPRESSION) {
+		t.Fatal("incorrect clientmessage type")
+	}
+	var c mysqlx_connection.Compression
+	if err := proto.Unmarshal(b[5:], &c); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if c.GetUncompressedSize() != 1024 || !bytes.Equal(c.GetPayload(), payload) {
+		t.Fatalf("incorrect compression %v", &c)
+	}
+}
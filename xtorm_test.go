@@ -3,6 +3,7 @@ package xtorm
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -157,6 +158,126 @@ func TestUpdateExecution(t *testing.T) {
 	fmt.Printf("%+v\n", r)
 }
 
+func TestFindExecution(t *testing.T) {
+	rows := make([][]interface{}, 20)
+	for i := range rows {
+		rows[i] = []interface{}{i + 1, strconv.Itoa(i + 1)}
+	}
+
+	var ref Ref
+	x := New(bufferSize)
+	x.ExpectFailOnError(OpenExpectCtxEmpty, func(b *Builder) error {
+		b.Tx(IsolationLevelDefault, func(b *Builder) error {
+			b.Delete("foo", nil)
+			b.Insert("foo", []string{"id", "val"}, rows)
+			ref = b.Ref()
+			b.FindF("foo", Gt(Column("id"), 10), func(f *Find) error {
+				f.Column("id")
+				f.Column("val")
+				f.OrderByDesc(Column("id"))
+				f.Limit(5, 0)
+				return nil
+			})
+			return nil
+		})
+		return nil
+	})
+
+	conn := NewConn(t)
+	defer conn.Close()
+	r, err := x.Send(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("send failed: %q", err)
+	}
+	found, err := ref.Rows(r)
+	if err != nil {
+		t.Fatalf("find failed: %s", err)
+	}
+	if columns := found.Columns(); len(columns) != 2 || columns[0] != "id" || columns[1] != "val" {
+		t.Fatalf("unexpected columns %v", columns)
+	}
+	n := 0
+	for found.Next() {
+		var id int
+		var val string
+
+		if err := found.Scan(&id, &val); err != nil {
+			t.Fatalf("failed to scan: %s", err)
+		}
+		if expected := 20 - n; id != expected || val != strconv.Itoa(expected) {
+			t.Fatalf("expected row %d, got %d %q", expected, id, val)
+		}
+		n++
+	}
+	if n != 5 {
+		t.Fatalf("expected 5 rows, got %d", n)
+	}
+}
+
+func TestDeleteOrderLimitExecution(t *testing.T) {
+	x := New(bufferSize)
+	x.ExpectFailOnError(OpenExpectCtxEmpty, func(b *Builder) error {
+		b.Tx(IsolationLevelDefault, func(b *Builder) error {
+			b.UpdateF("foo", nil, func(u *Update) error {
+				u.Set("val2", "latest")
+				u.OrderByDesc(Column("id"))
+				u.Limit(1)
+				return nil
+			})
+			b.DeleteF("foo", nil, func(d *Delete) error {
+				d.OrderBy(Column("id"))
+				d.Limit(5)
+				return nil
+			})
+			return nil
+		})
+		return nil
+	})
+
+	conn := NewConn(t)
+	defer conn.Close()
+	r, err := x.Send(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("send failed: %q", err)
+	}
+	fmt.Printf("%+v\n", r)
+}
+
+func TestPrepareCrudExecution(t *testing.T) {
+	const (
+		insertFooCrud uint32 = 100 + iota
+		findFooCrud
+	)
+	x := New(bufferSize)
+	x.ExpectFailOnError(OpenExpectCtxEmpty, func(b *Builder) error {
+		b.PrepareCrud(insertFooCrud, func(b *Builder) error {
+			b.Insert("foo", []string{"id", "val"}, [][]interface{}{{Placeholder(0), Placeholder(1)}})
+			return nil
+		})
+		b.PrepareCrud(findFooCrud, func(b *Builder) error {
+			b.Find("foo", []string{"id", "val"}, Eq(Column("id"), Placeholder(0)))
+			return nil
+		})
+		b.Tx(IsolationLevelDefault, func(b *Builder) error {
+			b.Delete("foo", nil)
+			b.Execute(insertFooCrud, 1, "one")
+			b.Execute(findFooCrud, 1)
+			return nil
+		})
+		b.Deallocate(insertFooCrud)
+		b.Deallocate(findFooCrud)
+		return nil
+	})
+
+	conn := NewConn(t)
+	defer conn.Close()
+	r, err := x.Send(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("send failed: %q", err)
+	}
+	fmt.Printf("%+v\n", r)
+}
+
 func TestLastInsertID(t *testing.T) {
 	//ERROR 5153 (HY000): Mysqlx::Expr::Expr::VARIABLE is not supported yet
 	t.Skip("Mysqlx::Expr::Expr::VARIABLE is currently unsupported in MySQL's X plugin")